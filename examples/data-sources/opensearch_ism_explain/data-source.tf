@@ -0,0 +1,14 @@
+data "opensearch_ism_explain" "demo" {
+  index = "demo-*"
+
+  lifecycle {
+    postcondition {
+      condition     = length(self.failed_indices) == 0
+      error_message = "Indices are stuck in a failed ism step: ${join(", ", self.failed_indices)}"
+    }
+  }
+}
+
+output "demo_ism_states" {
+  value = { for index in data.opensearch_ism_explain.demo.indices : index.index => index.state }
+}
@@ -0,0 +1,5 @@
+resource "opensearch_ism_managed_index" "demo" {
+  index = "demo-*"
+  policy_id = opensearch_ism_policy.demo.policy_id
+  state = "start"
+}
@@ -116,4 +116,35 @@ resource "opensearch_ism_policy" "demo" {
     index_patterns = ["staging*"]
     priority = 100
   }
+}
+
+resource "opensearch_ism_policy" "demo_json" {
+  policy_id = "demo_json"
+  policy_json = jsonencode({
+    description   = "demo"
+    default_state = "hot"
+    states = [
+      {
+        name    = "hot"
+        actions = []
+        transitions = [
+          {
+            state_name = "delete"
+            conditions {
+              min_index_age = "30d"
+            }
+          }
+        ]
+      },
+      {
+        name = "delete"
+        actions = [
+          {
+            delete = {}
+          }
+        ]
+        transitions = []
+      }
+    ]
+  })
 }
\ No newline at end of file
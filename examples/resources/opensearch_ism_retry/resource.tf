@@ -0,0 +1,8 @@
+resource "opensearch_ism_retry" "demo" {
+  index = "demo-*"
+  state = "start"
+
+  triggers = {
+    policy = sha1(jsonencode(opensearch_ism_policy.demo.states))
+  }
+}
@@ -1,12 +1,52 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"log"
+
 	"ferlab/terraform-provider-opensearch/provider"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6/tf6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf5to6server"
+	"github.com/hashicorp/terraform-plugin-mux/tf6muxserver"
 )
 
+//Address the provider is published at, which the state of its resources references.
+//Releases set it from the owner of the repository through the ldflags.
+var address string = "registry.terraform.io/Ferlab-Ste-Justine/opensearch"
+
 func main() {
-	plugin.Serve(&plugin.ServeOpts{
-		ProviderFunc: provider.Provider,
-	})
+	flag.StringVar(&address, "address", address, "Address the provider is published at")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	//Resources are being migrated from the sdk to the plugin framework, both are served through a mux in the meantime.
+	//The framework resources use nested attributes, which require the protocol 6 the sdk provider is upgraded to.
+	sdkServer, err := tf5to6server.UpgradeServer(ctx, provider.Provider().GRPCProvider)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	muxServer, err := tf6muxserver.NewMuxServer(
+		ctx,
+		func() tfprotov6.ProviderServer {
+			return sdkServer
+		},
+		provider.NewFrameworkProviderServer(),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = tf6server.Serve(
+		address,
+		func() tfprotov6.ProviderServer {
+			return muxServer.ProviderServer()
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceOpensearchIsmExplain() *schema.Resource {
+	return &schema.Resource{
+		Description: "Ism status of the indices matching a name, to detect the managed indices that are stuck in a failed step.",
+		ReadContext:   dataSourceOpensearchIsmExplainRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Description: "Name of the indices to explain. Wildcards and comma separated lists are supported.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"indices": {
+				Description: "Ism status of each index matching the name, sorted by index name. Indices that are not managed by a policy are listed with an empty policy id.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"index": {
+							Description: "Name of the index.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"index_uuid": {
+							Description: "Uuid of the index.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"policy_id": {
+							Description: "Id of the policy managing the index.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"enabled": {
+							Description: "Whether the policy is running on the index.",
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"state": {
+							Description: "Current state of the index in the policy.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"action": {
+							Description: "Current action of the index in its state.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"step": {
+							Description: "Current step of the action.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"step_status": {
+							Description: "Status of the current step: starting, condition_not_met, completed or failed.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"failed": {
+							Description: "Whether the index is stuck in a failed step.",
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"consumed_retries": {
+							Description: "Number of retries consumed by the current action.",
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"info_message": {
+							Description: "Message describing the outcome of the last step.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"info": {
+							Description: "Json document of all the information reported on the last step, including the cause of failures.",
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"failed_indices": {
+				Description: "Names of the indices that are stuck in a failed step, sorted by name.",
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func ismExplainIndexModelToSchema(explain IsmExplainIndexModel) (map[string]interface{}, error) {
+	infoStr := ""
+	if len(explain.Info) > 0 {
+		info, marErr := json.Marshal(explain.Info)
+		if marErr != nil {
+			return nil, marErr
+		}
+		infoStr = string(info)
+	}
+
+	index := map[string]interface{}{
+		"index":            explain.Index,
+		"index_uuid":       explain.IndexUuid,
+		"policy_id":        explain.GetPolicyId(),
+		"enabled":          explain.Enabled != nil && *explain.Enabled,
+		"state":            "",
+		"action":           "",
+		"step":             "",
+		"step_status":      "",
+		"failed":           explain.IsFailed(),
+		"consumed_retries": 0,
+		"info_message":     explain.GetInfoMessage(),
+		"info":             infoStr,
+	}
+
+	if explain.State != nil {
+		index["state"] = explain.State.Name
+	}
+
+	if explain.Action != nil {
+		index["action"] = explain.Action.Name
+		index["consumed_retries"] = explain.Action.ConsumedRetries
+	}
+
+	if explain.Step != nil {
+		index["step"] = explain.Step.Name
+		index["step_status"] = explain.Step.StepStatus
+	}
+
+	if explain.RetryInfo != nil && explain.RetryInfo.ConsumedRetries > 0 {
+		index["consumed_retries"] = explain.RetryInfo.ConsumedRetries
+	}
+
+	return index, nil
+}
+
+func dataSourceOpensearchIsmExplainRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cli := meta.(OpensearchClient)
+	index := d.Get("index").(string)
+
+	explanations, err := cli.GetRequestContext(ctx).ExplainIsmIndices(index)
+	if err != nil {
+		return diag.Errorf("Error retrieving the ism status of indices '%s': %s", index, err.Error())
+	}
+
+	names := []string{}
+	for name, _ := range explanations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	indices := []interface{}{}
+	failedIndices := []string{}
+	for _, name := range names {
+		explanation := explanations[name]
+		explainIndex, explainErr := ismExplainIndexModelToSchema(explanation)
+		if explainErr != nil {
+			return diag.Errorf("Error reading the ism status of index '%s': %s", name, explainErr.Error())
+		}
+		indices = append(indices, explainIndex)
+
+		if explanation.IsFailed() {
+			failedIndices = append(failedIndices, name)
+		}
+	}
+
+	d.SetId(index)
+	d.Set("indices", indices)
+	d.Set("failed_indices", failedIndices)
+
+	return nil
+}
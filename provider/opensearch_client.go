@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -8,19 +10,46 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 )
 
 type OpensearchClient struct {
-	Client    *http.Client
-	Endpoints []string
-	Username  string
-	Password  string
-	Retries   int
+	Client      *http.Client
+	Endpoints   []string
+	Username    string
+	Password    string
+	Token       string
+	TokenFile   string
+	ApiKey      string
+	Headers     map[string]string
+	Retries     int
+	RetryPolicy RetryPolicy
+	Health      *EndpointsHealth
+	Sniffer     *EndpointsSniffer
+	AwsSigner   *AwsSigner
+	Cluster     *ClusterInfo
+	Secrets     []string
 }
 
-func (cli *OpensearchClient) GetRequestContext() *RequestContext {
+//Endpoints requests should be sent to. They are the configured endpoints unless sniffing is enabled.
+func (cli *OpensearchClient) GetEndpoints(ctx context.Context) []string {
+	if (*cli).Sniffer == nil {
+		return (*cli).Endpoints
+	}
+
+	return (*cli).Sniffer.GetEndpoints(ctx, cli)
+}
+
+//Requests sent with the request context are cancelled when the operation's context is done
+func (cli *OpensearchClient) GetRequestContext(ctx context.Context) *RequestContext {
+	return cli.GetRequestContextOn(ctx, cli.GetEndpoints(ctx))
+}
+
+func (cli *OpensearchClient) GetRequestContextOn(ctx context.Context, endpoints []string) *RequestContext {
 	return &RequestContext{
 		Client: cli,
+		Ctx: cli.GetLogContext(ctx),
+		Endpoints: endpoints,
 		CurrentEndpoint: 0,
 		RetriesLeft: (*cli).Retries,
 	}
@@ -28,16 +57,18 @@ func (cli *OpensearchClient) GetRequestContext() *RequestContext {
 
 type RequestContext struct {
 	Client          *OpensearchClient
+	Ctx             context.Context
+	Endpoints       []string
 	CurrentEndpoint int
 	RetriesLeft     int
 }
 
 func (reqCon *RequestContext) GetCurrentEndpoint() string {
-	return (*(*reqCon).Client).Endpoints[(*reqCon).CurrentEndpoint]
+	return (*reqCon).Endpoints[(*reqCon).CurrentEndpoint]
 }
 
 func (reqCon *RequestContext) AtLastEndpoint() bool {
-	return ((*reqCon).CurrentEndpoint + 1) == len((*(*reqCon).Client).Endpoints)
+	return ((*reqCon).CurrentEndpoint + 1) == len((*reqCon).Endpoints)
 }
 
 func inArr(val int64, arr []int64) bool {
@@ -49,7 +80,81 @@ func inArr(val int64, arr []int64) bool {
 	return false
 }
 
-func (reqCon *RequestContext) Do(method string, urlPath string, queryString string, body string, okBadCodes []int64) (*http.Response, error) {
+func (reqCon *RequestContext) NextEndpoint() {
+	if reqCon.AtLastEndpoint() {
+		(*reqCon).CurrentEndpoint = 0
+	} else {
+		(*reqCon).CurrentEndpoint += 1
+	}
+}
+
+//Moves to the first endpoint, starting from the current one, that is healthy enough to receive a request.
+//If all the endpoints are unhealthy, the current endpoint is kept as there is no better alternative.
+func (reqCon *RequestContext) SelectHealthyEndpoint() {
+	endpointsCount := len((*reqCon).Endpoints)
+	for idx := 0; idx < endpointsCount; idx++ {
+		candidate := ((*reqCon).CurrentEndpoint + idx) % endpointsCount
+		if (*(*reqCon).Client).Health.Acquire((*reqCon).Endpoints[candidate]) {
+			(*reqCon).CurrentEndpoint = candidate
+			return
+		}
+	}
+}
+
+//Waits before the next attempt, consuming one of the retries left.
+//The wait is interrupted, with an error, if the operation is cancelled or times out in the meantime.
+func (reqCon *RequestContext) WaitForRetry(res *http.Response) error {
+	attempt := (*(*reqCon).Client).Retries - (*reqCon).RetriesLeft
+	(*reqCon).RetriesLeft -= 1
+	delay := (*(*reqCon).Client).RetryPolicy.GetDelay(attempt, res)
+	reqCon.LogRetry(delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-(*reqCon).Ctx.Done():
+		return (*reqCon).Ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+//Adds the credentials to the request. It should be called last as aws signatures cover the request's headers.
+//Authentication modes are mutually exclusive, but if several were set, they would be used in this order of precedence:
+//aws signature, bearer token, bearer token file, api key and basic authentication.
+func (cli *OpensearchClient) Authenticate(req *http.Request, body string) error {
+	if (*cli).AwsSigner != nil {
+		return (*cli).AwsSigner.Sign(req, body)
+	}
+
+	if (*cli).Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", (*cli).Token))
+		return nil
+	}
+
+	//The file is read on every request so that short-lived tokens can be rotated while the provider runs
+	if (*cli).TokenFile != "" {
+		token, tokenErr := ioutil.ReadFile((*cli).TokenFile)
+		if tokenErr != nil {
+			return errors.New(fmt.Sprintf("Failed to read token file: %s", tokenErr.Error()))
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", strings.TrimSpace(string(token))))
+		return nil
+	}
+
+	if (*cli).ApiKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("ApiKey %s", (*cli).ApiKey))
+		return nil
+	}
+
+	if (*cli).Username != "" {
+		req.SetBasicAuth((*cli).Username, (*cli).Password)
+	}
+
+	return nil
+}
+
+func (reqCon *RequestContext) NewRequest(method string, urlPath string, queryString string, body string) (*http.Request, error) {
 	endpoint := reqCon.GetCurrentEndpoint()
 	u, uErr := url.Parse(endpoint)
 	if uErr != nil {
@@ -61,62 +166,103 @@ func (reqCon *RequestContext) Do(method string, urlPath string, queryString stri
 	}
 
 	r := strings.NewReader(body)
-	req, reqErr := http.NewRequest(method, u.String(), r)
-    if reqErr != nil {
+	req, reqErr := http.NewRequestWithContext((*reqCon).Ctx, method, u.String(), r)
+	if reqErr != nil {
 		return nil, reqErr
 	}
 
-	if (*(*reqCon).Client).Username != "" {
-		req.SetBasicAuth ((*(*reqCon).Client).Username, (*(*reqCon).Client).Password)
+	for key, val := range (*(*reqCon).Client).Headers {
+		//Golang ignores the host header and takes the host from a dedicated field instead
+		if strings.EqualFold(key, "Host") {
+			req.Host = val
+			continue
+		}
+		req.Header.Set(key, val)
 	}
 
 	if body != "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	res, resErr := (*(*reqCon).Client).Client.Do(req)
-	if resErr != nil {
-		if (*reqCon).RetriesLeft == 0 {
-			return res, resErr	
-		}
+	authErr := (*reqCon).Client.Authenticate(req, body)
+	if authErr != nil {
+		return nil, authErr
+	}
 
-		(*reqCon).RetriesLeft -= 1
+	return req, nil
+}
 
-		if reqCon.AtLastEndpoint() {
-			(*reqCon).CurrentEndpoint = 0
-		} else {
-			(*reqCon).CurrentEndpoint += 1
+//Sends a request, moving to the next endpoint and backing off between attempts when the request fails in a retriable way.
+//Responses with a status code in okBadCodes are returned to the caller as is rather than being treated as errors.
+//The response body is fully read, so the caller can consume it after the connection is released.
+func (reqCon *RequestContext) Do(method string, urlPath string, queryString string, body string, okBadCodes []int64) (*http.Response, error) {
+	health := (*(*reqCon).Client).Health
+	reqCon.SelectHealthyEndpoint()
+	for {
+		endpoint := reqCon.GetCurrentEndpoint()
+		req, reqErr := reqCon.NewRequest(method, urlPath, queryString, body)
+		if reqErr != nil {
+			return nil, reqErr
 		}
-		
-		return reqCon.Do(method, urlPath, queryString, body, okBadCodes)
-	}
 
-	if res.StatusCode >= 400 && !inArr(int64(res.StatusCode), okBadCodes) {
-		defer res.Body.Close()
+		reqCon.LogRequest(req, body)
+		start := time.Now()
+		res, resErr := (*(*reqCon).Client).Client.Do(req)
+		var b []byte
+		if resErr == nil {
+			b, resErr = ioutil.ReadAll(res.Body)
+			res.Body.Close()
+		}
+		latency := time.Since(start)
 
-		if (*reqCon).RetriesLeft == 0 {
-			b, bErr := ioutil.ReadAll(res.Body)
-			var errMsg string
-			if bErr != nil {
-				errMsg = "Could not extract error code response body"
-			} else {
-				errMsg = string(b)
+		if resErr != nil {
+			reqCon.LogTransportError(req, resErr, latency)
+
+			//The request was aborted by the operation's cancellation or timeout, not by a failure of the endpoint
+			if (*reqCon).Ctx.Err() != nil {
+				return nil, resErr
 			}
 
-			errMsg = fmt.Sprintf("Request return code %d: %s", res.StatusCode, errMsg)
-			return res, errors.New(errMsg)
+			health.ReportFailure(endpoint)
+			if (*reqCon).RetriesLeft == 0 {
+				return nil, resErr
+			}
+
+			waitErr := reqCon.WaitForRetry(nil)
+			if waitErr != nil {
+				return nil, resErr
+			}
+			reqCon.NextEndpoint()
+			reqCon.SelectHealthyEndpoint()
+			continue
 		}
 
-		(*reqCon).RetriesLeft -= 1
+		res.Body = ioutil.NopCloser(bytes.NewReader(b))
+		reqCon.LogResponse(req, res, b, latency)
 
-		if reqCon.AtLastEndpoint() {
-			(*reqCon).CurrentEndpoint = 0
-		} else {
-			(*reqCon).CurrentEndpoint += 1
+		if res.StatusCode < 400 || inArr(int64(res.StatusCode), okBadCodes) {
+			health.ReportSuccess(endpoint)
+			return res, nil
+		}
+
+		errMsg := string(b)
+
+		//Terminal errors are caused by the request, not by the endpoint that processed it
+		if !(*(*reqCon).Client).RetryPolicy.IsRetriableResponse(req, res.StatusCode, errMsg) {
+			health.ReportSuccess(endpoint)
+			return res, NewOpensearchError(endpoint, req, res, errMsg)
 		}
-		
-		return reqCon.Do(method, urlPath, queryString, body, okBadCodes)
-	}
 
-	return res, nil
-}
\ No newline at end of file
+		health.ReportFailure(endpoint)
+		if (*reqCon).RetriesLeft == 0 {
+			return res, NewOpensearchError(endpoint, req, res, errMsg)
+		}
+
+		waitErr := reqCon.WaitForRetry(res)
+		if waitErr != nil {
+			return res, NewOpensearchError(endpoint, req, res, errMsg)
+		}
+		reqCon.NextEndpoint()
+		reqCon.SelectHealthyEndpoint()
+	}
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+//Credentials are refreshed this long before they expire so that they are not rejected in flight
+const awsCredentialsExpiryWindow = 5 * time.Minute
+
+type AwsAuthConfig struct {
+	Region               string
+	Service              string
+	AccessKey            string
+	SecretKey            string
+	SessionToken         string
+	Profile              string
+	AssumeRoleArn        string
+	SessionName          string
+	WebIdentityTokenFile string
+}
+
+type AwsSigner struct {
+	Region      string
+	Service     string
+	Credentials aws.CredentialsProvider
+	Signer      *v4.Signer
+}
+
+//Resolves the credentials from, by order of precedence, the static keys, the profile or the default aws credentials chain.
+//The resolved credentials are then used to assume a role if one is specified.
+func NewAwsSigner(conf AwsAuthConfig) (*AwsSigner, error) {
+	loadOpts := []func(*config.LoadOptions) error{
+		config.WithRegion(conf.Region),
+	}
+
+	if conf.AccessKey != "" {
+		loadOpts = append(loadOpts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(conf.AccessKey, conf.SecretKey, conf.SessionToken),
+		))
+	} else if conf.Profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(conf.Profile))
+	}
+
+	awsConf, awsConfErr := config.LoadDefaultConfig(context.Background(), loadOpts...)
+	if awsConfErr != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to load aws configuration: %s", awsConfErr.Error()))
+	}
+
+	creds := awsConf.Credentials
+	if conf.WebIdentityTokenFile != "" {
+		if conf.AssumeRoleArn == "" {
+			return nil, errors.New("assume_role_arn is required when web_identity_token_file is specified")
+		}
+
+		creds = stscreds.NewWebIdentityRoleProvider(
+			sts.NewFromConfig(awsConf),
+			conf.AssumeRoleArn,
+			stscreds.IdentityTokenFile(conf.WebIdentityTokenFile),
+			func(opts *stscreds.WebIdentityRoleOptions) {
+				opts.RoleSessionName = conf.SessionName
+			},
+		)
+	} else if conf.AssumeRoleArn != "" {
+		creds = stscreds.NewAssumeRoleProvider(
+			sts.NewFromConfig(awsConf),
+			conf.AssumeRoleArn,
+			func(opts *stscreds.AssumeRoleOptions) {
+				opts.RoleSessionName = conf.SessionName
+			},
+		)
+	}
+
+	if creds == nil {
+		return nil, errors.New("Failed to find aws credentials")
+	}
+
+	return &AwsSigner{
+		Region:  conf.Region,
+		Service: conf.Service,
+		Credentials: aws.NewCredentialsCache(creds, func(opts *aws.CredentialsCacheOptions) {
+			opts.ExpiryWindow = awsCredentialsExpiryWindow
+		}),
+		Signer: v4.NewSigner(),
+	}, nil
+}
+
+//Signs the request with aws signature version 4, including the hash of the body.
+//The request should be fully formed beforehand as headers added afterwards will not be signed.
+func (sig *AwsSigner) Sign(req *http.Request, body string) error {
+	creds, credsErr := (*sig).Credentials.Retrieve(req.Context())
+	if credsErr != nil {
+		return errors.New(fmt.Sprintf("Failed to retrieve aws credentials: %s", credsErr.Error()))
+	}
+
+	bodyHash := sha256.Sum256([]byte(body))
+	payloadHash := hex.EncodeToString(bodyHash[:])
+	//Opensearch serverless requires the payload hash to be passed explicitly
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	return (*sig).Signer.SignHTTP(req.Context(), creds, req, payloadHash, (*sig).Service, (*sig).Region, time.Now())
+}
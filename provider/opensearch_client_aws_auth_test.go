@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const (
+	testAwsAccessKey = "AKIDEXAMPLE"
+	testAwsSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testAwsRegion    = "us-east-1"
+	testAwsService   = "es"
+)
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data string) string {
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
+func canonicalAwsQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, val := range values {
+			parts = append(parts, fmt.Sprintf("%s=%s", url.QueryEscape(key), strings.ReplaceAll(url.QueryEscape(val), "+", "%20")))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+//Independent implementation of the aws signature version 4 verification, as done by the aws endpoints
+func verifyAwsSignature(req *http.Request, body string) error {
+	authorization := req.Header.Get("Authorization")
+	prefix := "AWS4-HMAC-SHA256 "
+	if !strings.HasPrefix(authorization, prefix) {
+		return errors.New(fmt.Sprintf("unexpected authorization header '%s'", authorization))
+	}
+
+	authParts := make(map[string]string)
+	for _, part := range strings.Split(strings.TrimPrefix(authorization, prefix), ",") {
+		keyVal := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(keyVal) == 2 {
+			authParts[keyVal[0]] = keyVal[1]
+		}
+	}
+
+	credential := strings.Split(authParts["Credential"], "/")
+	if len(credential) != 5 || credential[0] != testAwsAccessKey || credential[2] != testAwsRegion || credential[3] != testAwsService || credential[4] != "aws4_request" {
+		return errors.New(fmt.Sprintf("unexpected credential scope '%s'", authParts["Credential"]))
+	}
+
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash != sha256Hex(body) {
+		return errors.New("the payload hash does not match the body")
+	}
+
+	signedHeaders := strings.Split(authParts["SignedHeaders"], ";")
+	canonicalHeaders := ""
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		switch name {
+		case "host":
+			value = req.Host
+		case "content-length":
+			value = strconv.FormatInt(req.ContentLength, 10)
+		}
+		canonicalHeaders += fmt.Sprintf("%s:%s\n", name, strings.TrimSpace(value))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalAwsQuery(req.URL.Query()),
+		canonicalHeaders,
+		authParts["SignedHeaders"],
+		payloadHash,
+	}, "\n")
+
+	amzDate := req.Header.Get("X-Amz-Date")
+	scope := strings.Join(credential[1:], "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	key := hmacSha256([]byte("AWS4"+testAwsSecretKey), credential[1])
+	key = hmacSha256(key, testAwsRegion)
+	key = hmacSha256(key, testAwsService)
+	key = hmacSha256(key, "aws4_request")
+	expected := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	if !hmac.Equal([]byte(expected), []byte(authParts["Signature"])) {
+		return errors.New("the signature does not match")
+	}
+
+	return nil
+}
+
+func newAwsVerifyingServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, readErr := io.ReadAll(r.Body)
+		if readErr != nil {
+			t.Fatalf("Failed to read the request body: %s", readErr.Error())
+		}
+
+		verifyErr := verifyAwsSignature(r, string(body))
+		if verifyErr != nil {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(verifyErr.Error()))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestAwsSignerSign(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	server := newAwsVerifyingServer(t)
+	defer server.Close()
+
+	signer, signerErr := NewAwsSigner(AwsAuthConfig{
+		Region:    testAwsRegion,
+		Service:   testAwsService,
+		AccessKey: testAwsAccessKey,
+		SecretKey: testAwsSecretKey,
+	})
+	if signerErr != nil {
+		t.Fatalf("Failed to create the signer: %s", signerErr.Error())
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		tamper         func(req *http.Request) string
+		expectedStatus int
+	}{
+		{
+			name:           "request without a body",
+			method:         "GET",
+			path:           "/_plugins/_ism/policies/demo",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "request with a body and a query",
+			method:         "PUT",
+			path:           "/_plugins/_ism/policies/demo?if_seq_no=1&if_primary_term=2",
+			body:           `{"policy":{"description":"demo"}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "body changed after signing",
+			method: "PUT",
+			path:   "/_plugins/_security/api/roles/demo",
+			body:   `{"cluster_permissions":[]}`,
+			tamper: func(req *http.Request) string {
+				return `{"cluster_permissions":["*"]}`
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "signed header changed after signing",
+			method: "GET",
+			path:   "/_cluster/health",
+			tamper: func(req *http.Request) string {
+				req.Header.Set("X-Amz-Date", "20000101T000000Z")
+				return ""
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, reqErr := http.NewRequestWithContext(context.Background(), tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			if reqErr != nil {
+				t.Fatalf("Failed to create the request: %s", reqErr.Error())
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			signErr := signer.Sign(req, tt.body)
+			if signErr != nil {
+				t.Fatalf("Failed to sign the request: %s", signErr.Error())
+			}
+
+			if tt.tamper != nil {
+				body := tt.tamper(req)
+				req.Body = io.NopCloser(strings.NewReader(body))
+				req.ContentLength = int64(len(body))
+			}
+
+			res, resErr := server.Client().Do(req)
+			if resErr != nil {
+				t.Fatalf("Failed to send the request: %s", resErr.Error())
+			}
+			defer res.Body.Close()
+
+			resBody, _ := io.ReadAll(res.Body)
+			if res.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, res.StatusCode, string(resBody))
+			}
+		})
+	}
+}
+
+func TestNewAwsSignerRequiresRoleForWebIdentity(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	_, signerErr := NewAwsSigner(AwsAuthConfig{
+		Region:               testAwsRegion,
+		Service:              testAwsService,
+		WebIdentityTokenFile: "/var/run/secrets/token",
+	})
+	if signerErr == nil || !strings.Contains(signerErr.Error(), "assume_role_arn") {
+		t.Errorf("Expected an error about the missing assume_role_arn, got %v", signerErr)
+	}
+}
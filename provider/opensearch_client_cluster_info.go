@@ -0,0 +1,221 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	SecurityPlugin = "security"
+	IsmPlugin      = "index-management"
+)
+
+//Root of the api paths of each plugin, under either the _plugins or the _opendistro prefix
+var pluginApiRoots = map[string]string{
+	SecurityPlugin: "_security",
+	IsmPlugin:      "_ism",
+}
+
+var pluginDisplayNames = map[string]string{
+	SecurityPlugin: "security",
+	IsmPlugin:      "index state management (ISM)",
+}
+
+type ClusterVersionModel struct {
+	Distribution string `json:"distribution"`
+	Number       string `json:"number"`
+}
+
+type ClusterRootModel struct {
+	Version ClusterVersionModel `json:"version"`
+}
+
+type ClusterPluginModel struct {
+	Name      string `json:"name"`
+	Component string `json:"component"`
+}
+
+//Detection is a best effort that runs on every configuration of the provider, so it should fail fast rather than retry
+const clusterDetectionTimeout = 10 * time.Second
+
+type UnsupportedClusterError struct {
+	Distribution string
+	Version      string
+}
+
+func (ucErr *UnsupportedClusterError) Error() string {
+	distribution := (*ucErr).Distribution
+	if distribution == "" {
+		distribution = "elasticsearch"
+	}
+
+	return fmt.Sprintf("The cluster (%s %s) is neither an opensearch cluster nor an open distro for elasticsearch cluster", distribution, (*ucErr).Version)
+}
+
+func IsUnsupportedCluster(err error) bool {
+	var ucErr *UnsupportedClusterError
+	return errors.As(err, &ucErr)
+}
+
+type ClusterInfo struct {
+	//Either opensearch or opendistro for legacy open distro for elasticsearch clusters
+	Distribution string
+	Version      string
+	Plugins      []string
+}
+
+//Plugin components are prefixed differently depending on the distribution (ex: opensearch-security, opendistro_security)
+func (info *ClusterInfo) HasPlugin(plugin string) bool {
+	for _, component := range (*info).Plugins {
+		normalized := strings.ReplaceAll(component, "_", "-")
+		if strings.HasSuffix(normalized, "-"+plugin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (reqCon *RequestContext) GetClusterRoot() (*ClusterRootModel, error) {
+	res, err := reqCon.Do(
+		"GET",
+		"/",
+		"",
+		"",
+		[]int64{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, bErr := ioutil.ReadAll(res.Body)
+	if bErr != nil {
+		return nil, bErr
+	}
+
+	root := ClusterRootModel{}
+	uErr := json.Unmarshal(b, &root)
+	if uErr != nil {
+		return nil, uErr
+	}
+
+	return &root, nil
+}
+
+func (reqCon *RequestContext) GetClusterPlugins() ([]ClusterPluginModel, error) {
+	res, err := reqCon.Do(
+		"GET",
+		"_cat/plugins",
+		"format=json",
+		"",
+		[]int64{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, bErr := ioutil.ReadAll(res.Body)
+	if bErr != nil {
+		return nil, bErr
+	}
+
+	plugins := []ClusterPluginModel{}
+	uErr := json.Unmarshal(b, &plugins)
+	if uErr != nil {
+		return nil, uErr
+	}
+
+	return plugins, nil
+}
+
+//Open distro for elasticsearch reports itself as elasticsearch, only the components of its plugins, prefixed with opendistro, tell them apart
+func isOpendistroCluster(plugins []string) bool {
+	for _, plugin := range plugins {
+		if strings.HasPrefix(plugin, "opendistro") {
+			return true
+		}
+	}
+
+	return false
+}
+
+//The plugins are listed for each node. They should be the same on all nodes so duplicates are ignored.
+func (reqCon *RequestContext) DetectCluster() (*ClusterInfo, error) {
+	root, rootErr := reqCon.GetClusterRoot()
+	if rootErr != nil {
+		return nil, rootErr
+	}
+
+	plugins, pluginsErr := reqCon.GetClusterPlugins()
+	if pluginsErr != nil {
+		return nil, pluginsErr
+	}
+
+	info := ClusterInfo{
+		Distribution: "opensearch",
+		Version:      root.Version.Number,
+		Plugins:      []string{},
+	}
+
+	seen := make(map[string]bool)
+	for _, plugin := range plugins {
+		if !seen[plugin.Component] {
+			seen[plugin.Component] = true
+			info.Plugins = append(info.Plugins, plugin.Component)
+		}
+	}
+
+	if root.Version.Distribution != "opensearch" {
+		if !isOpendistroCluster(info.Plugins) {
+			return nil, &UnsupportedClusterError{Distribution: root.Version.Distribution, Version: info.Version}
+		}
+		info.Distribution = "opendistro"
+	}
+
+	return &info, nil
+}
+
+//Detection is done with a single attempt bounded by a short timeout
+func (cli *OpensearchClient) DetectCluster(ctx context.Context) (*ClusterInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, clusterDetectionTimeout)
+	defer cancel()
+
+	reqCon := cli.GetRequestContext(ctx)
+	(*reqCon).RetriesLeft = 0
+	return reqCon.DetectCluster()
+}
+
+//Legacy open distro for elasticsearch clusters expose the plugins' apis under _opendistro instead of _plugins.
+//If the cluster could not be detected, opensearch is assumed.
+func (cli *OpensearchClient) GetPluginApiPath(plugin string, elems ...string) string {
+	prefix := "_plugins"
+	if (*cli).Cluster != nil && (*cli).Cluster.Distribution == "opendistro" {
+		prefix = "_opendistro"
+	}
+
+	return path.Join(append([]string{prefix, pluginApiRoots[plugin]}, elems...)...)
+}
+
+//If the cluster could not be detected, the plugin is assumed to be present and requests are left to fail on their own
+func (cli *OpensearchClient) RequirePlugin(plugin string) error {
+	if (*cli).Cluster == nil || (*cli).Cluster.HasPlugin(plugin) {
+		return nil
+	}
+
+	return errors.New(fmt.Sprintf(
+		"The %s plugin is not installed on the cluster (%s %s)",
+		pluginDisplayNames[plugin],
+		(*cli).Cluster.Distribution,
+		(*cli).Cluster.Version,
+	))
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDetectCluster(t *testing.T) {
+	tests := []struct {
+		name                 string
+		root                 string
+		plugins              string
+		expectedDistribution string
+		unsupported          bool
+	}{
+		{
+			name:                 "opensearch",
+			root:                 `{"version":{"distribution":"opensearch","number":"2.4.0"}}`,
+			plugins:              `[{"name":"node-1","component":"opensearch-security"},{"name":"node-2","component":"opensearch-security"}]`,
+			expectedDistribution: "opensearch",
+		},
+		{
+			name:                 "open distro",
+			root:                 `{"version":{"number":"7.10.2"}}`,
+			plugins:              `[{"name":"node-1","component":"opendistro_security"},{"name":"node-1","component":"opendistro-index-management"}]`,
+			expectedDistribution: "opendistro",
+		},
+		{
+			name:        "elasticsearch without open distro plugins",
+			root:        `{"version":{"number":"7.17.0"}}`,
+			plugins:     `[{"name":"node-1","component":"analysis-icu"}]`,
+			unsupported: true,
+		},
+		{
+			name:        "other distribution",
+			root:        `{"version":{"distribution":"other","number":"1.0.0"}}`,
+			plugins:     `[]`,
+			unsupported: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/":
+					w.Write([]byte(tt.root))
+				case "/_cat/plugins":
+					w.Write([]byte(tt.plugins))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			cli := OpensearchClient{
+				Client:    &http.Client{},
+				Endpoints: []string{server.URL},
+				Health:    NewEndpointsHealth(3, time.Minute),
+			}
+
+			info, err := cli.DetectCluster(context.Background())
+			if tt.unsupported {
+				if !IsUnsupportedCluster(err) {
+					t.Fatalf("Expected an unsupported cluster error, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+			if info.Distribution != tt.expectedDistribution {
+				t.Errorf("Expected distribution '%s', got '%s'", tt.expectedDistribution, info.Distribution)
+			}
+			if len(info.Plugins) == 0 || len(info.Plugins) > 2 {
+				t.Errorf("Expected the duplicate plugins to be ignored, got %v", info.Plugins)
+			}
+		})
+	}
+}
+
+func TestDetectClusterMakesASingleAttempt(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts += 1
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cli := OpensearchClient{
+		Client:      &http.Client{},
+		Endpoints:   []string{server.URL},
+		Retries:     5,
+		RetryPolicy: RetryPolicy{MinDelay: time.Second, MaxDelay: time.Second, RetryOnStatusCodes: GetDefaultRetryOnStatusCodes()},
+		Health:      NewEndpointsHealth(10, time.Minute),
+	}
+
+	_, err := cli.DetectCluster(context.Background())
+	if err == nil || IsUnsupportedCluster(err) {
+		t.Fatalf("Expected the detection to fail, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt, got %d", attempts)
+	}
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"sync"
+	"time"
+)
+
+type BreakerState int
+
+const (
+	//Endpoint is healthy and receives requests
+	BreakerClosed BreakerState = iota
+	//Endpoint failed repeatedly and is skipped until its cooldown expires
+	BreakerOpen
+	//Cooldown expired and a single probe request is let through to determine if the endpoint recovered
+	BreakerHalfOpen
+)
+
+type EndpointHealth struct {
+	ConsecutiveFailures int
+	LastFailure         time.Time
+	ProbeStart          time.Time
+	State               BreakerState
+}
+
+//Health of the endpoints, shared by all the requests the provider makes.
+//Terraform runs operations on resources in parallel so access to it is synchronized.
+type EndpointsHealth struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+	lock             sync.Mutex
+	endpoints        map[string]*EndpointHealth
+}
+
+func NewEndpointsHealth(failureThreshold int, cooldown time.Duration) *EndpointsHealth {
+	return &EndpointsHealth{
+		FailureThreshold: failureThreshold,
+		Cooldown:         cooldown,
+		endpoints:        make(map[string]*EndpointHealth),
+	}
+}
+
+func (health *EndpointsHealth) get(endpoint string) *EndpointHealth {
+	endpointHealth, ok := (*health).endpoints[endpoint]
+	if !ok {
+		endpointHealth = &EndpointHealth{State: BreakerClosed}
+		(*health).endpoints[endpoint] = endpointHealth
+	}
+	return endpointHealth
+}
+
+//Determines if a request can be sent to the endpoint right away.
+//If the endpoint's cooldown expired, the endpoint goes in half-open state and the caller is given the probe request.
+//If a probe is already in progress, other callers are turned away until it completes or it has been pending for a whole cooldown.
+func (health *EndpointsHealth) Acquire(endpoint string) bool {
+	(*health).lock.Lock()
+	defer (*health).lock.Unlock()
+
+	endpointHealth := health.get(endpoint)
+	now := time.Now()
+
+	switch (*endpointHealth).State {
+	case BreakerOpen:
+		if now.Sub((*endpointHealth).LastFailure) < (*health).Cooldown {
+			return false
+		}
+		(*endpointHealth).State = BreakerHalfOpen
+		(*endpointHealth).ProbeStart = now
+		return true
+	case BreakerHalfOpen:
+		if now.Sub((*endpointHealth).ProbeStart) < (*health).Cooldown {
+			return false
+		}
+		(*endpointHealth).ProbeStart = now
+		return true
+	}
+
+	return true
+}
+
+func (health *EndpointsHealth) ReportSuccess(endpoint string) {
+	(*health).lock.Lock()
+	defer (*health).lock.Unlock()
+
+	endpointHealth := health.get(endpoint)
+	(*endpointHealth).ConsecutiveFailures = 0
+	(*endpointHealth).State = BreakerClosed
+}
+
+func (health *EndpointsHealth) ReportFailure(endpoint string) {
+	(*health).lock.Lock()
+	defer (*health).lock.Unlock()
+
+	endpointHealth := health.get(endpoint)
+	(*endpointHealth).ConsecutiveFailures += 1
+	(*endpointHealth).LastFailure = time.Now()
+	if (*endpointHealth).State == BreakerHalfOpen || (*endpointHealth).ConsecutiveFailures >= (*health).FailureThreshold {
+		(*endpointHealth).State = BreakerOpen
+	}
+}
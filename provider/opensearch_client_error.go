@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type OpensearchErrorCauseModel struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+type OpensearchErrorDetailsModel struct {
+	Type      string                      `json:"type"`
+	Reason    string                      `json:"reason"`
+	RootCause []OpensearchErrorCauseModel `json:"root_cause"`
+}
+
+//Opensearch apis report errors in one of two formats:
+//The standard one: {"error": {"type": "...", "reason": "...", "root_cause": [...]}, "status": 404}
+//The security plugin's one: {"status": "NOT_FOUND", "message": "..."}
+//The status is a number in the former and a string in the latter.
+type OpensearchErrorBodyModel struct {
+	Error   json.RawMessage `json:"error"`
+	Status  json.RawMessage `json:"status"`
+	Message string          `json:"message"`
+}
+
+type OpensearchError struct {
+	StatusCode     int
+	Endpoint       string
+	Method         string
+	Path           string
+	Type           string
+	Reason         string
+	RootCause      []OpensearchErrorCauseModel
+	SecurityStatus string
+	Message        string
+	Body           string
+}
+
+func NewOpensearchError(endpoint string, req *http.Request, res *http.Response, body string) *OpensearchError {
+	osErr := OpensearchError{
+		StatusCode: res.StatusCode,
+		Endpoint:   endpoint,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		RootCause:  []OpensearchErrorCauseModel{},
+		Body:       body,
+	}
+
+	errBody := OpensearchErrorBodyModel{}
+	uErr := json.Unmarshal([]byte(body), &errBody)
+	if uErr != nil {
+		return &osErr
+	}
+
+	osErr.Message = errBody.Message
+
+	details := OpensearchErrorDetailsModel{}
+	if json.Unmarshal(errBody.Error, &details) == nil {
+		osErr.Type = details.Type
+		osErr.Reason = details.Reason
+		if details.RootCause != nil {
+			osErr.RootCause = details.RootCause
+		}
+	} else {
+		//Some apis report the error as a plain string
+		_ = json.Unmarshal(errBody.Error, &osErr.Reason)
+	}
+
+	_ = json.Unmarshal(errBody.Status, &osErr.SecurityStatus)
+
+	return &osErr
+}
+
+//Suggests what is likely wrong for errors that are caused by the provider's configuration rather than by the request
+func (osErr *OpensearchError) GetHint() string {
+	switch (*osErr).StatusCode {
+	case 401:
+		return "the credentials of the provider were rejected by the cluster"
+	case 403:
+		permission := getRequiredPermission((*osErr).Method, (*osErr).Path)
+		if permission != "" {
+			return fmt.Sprintf("the provider user lacks the `%s` permission", permission)
+		}
+		return "the provider user lacks the permissions to perform this operation"
+	}
+
+	return ""
+}
+
+//Bodies that could not be parsed are redacted and truncated like in the logs since some apis echo the content of the request in their errors
+func (osErr *OpensearchError) Error() string {
+	details := RedactBody((*osErr).Body)
+	if (*osErr).Type != "" {
+		details = fmt.Sprintf("%s: %s", (*osErr).Type, (*osErr).Reason)
+		for _, cause := range (*osErr).RootCause {
+			if cause.Type != (*osErr).Type || cause.Reason != (*osErr).Reason {
+				details = fmt.Sprintf("%s, caused by %s: %s", details, cause.Type, cause.Reason)
+			}
+		}
+	} else if (*osErr).Reason != "" {
+		details = (*osErr).Reason
+	} else if (*osErr).Message != "" {
+		details = (*osErr).Message
+	}
+
+	msg := fmt.Sprintf(
+		"Request %s %s on %s returned code %d: %s",
+		(*osErr).Method,
+		(*osErr).Path,
+		(*osErr).Endpoint,
+		(*osErr).StatusCode,
+		details,
+	)
+
+	hint := osErr.GetHint()
+	if hint != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, hint)
+	}
+
+	return msg
+}
+
+//Permissions required by the apis the provider uses, keyed by the path fragment that identifies each api
+var securityApiPermissions = map[string]string{
+	"/api/roles/":         "restapi:admin/roles",
+	"/api/rolesmapping/":  "restapi:admin/rolesmapping",
+	"/api/internalusers/": "restapi:admin/internalusers",
+}
+
+var ismManagedIndexApiPermissions = map[string]string{
+	"/_ism/explain/":       "cluster:admin/opendistro/ism/managedindex/explain",
+	"/_ism/change_policy/": "cluster:admin/opendistro/ism/managedindex/change",
+	"/_ism/add/":           "cluster:admin/opendistro/ism/managedindex/add",
+	"/_ism/remove/":        "cluster:admin/opendistro/ism/managedindex/remove",
+	"/_ism/retry/":         "cluster:admin/opendistro/ism/managedindex/retry",
+}
+
+func getRequiredPermission(method string, urlPath string) string {
+	if strings.Contains(urlPath, "/_security/") {
+		for fragment, permission := range securityApiPermissions {
+			if strings.Contains(urlPath+"/", fragment) {
+				return permission
+			}
+		}
+	}
+
+	if strings.Contains(urlPath, "/_ism/") {
+		for fragment, permission := range ismManagedIndexApiPermissions {
+			if strings.Contains(urlPath+"/", fragment) {
+				return permission
+			}
+		}
+
+		if strings.Contains(urlPath, "/policies/") {
+			switch method {
+			case "GET":
+				return "cluster:admin/opendistro/ism/policy/get"
+			case "DELETE":
+				return "cluster:admin/opendistro/ism/policy/delete"
+			default:
+				return "cluster:admin/opendistro/ism/policy/write"
+			}
+		}
+	}
+
+	return ""
+}
+
+func getOpensearchErrorStatusCode(err error) int {
+	var osErr *OpensearchError
+	if errors.As(err, &osErr) {
+		return osErr.StatusCode
+	}
+
+	return 0
+}
+
+//Some apis answer successfully, without the requested object, when it does not exist
+type NotFoundError struct {
+	Kind string
+	Name string
+}
+
+func (nfErr *NotFoundError) Error() string {
+	return fmt.Sprintf("%s '%s' was not found", (*nfErr).Kind, (*nfErr).Name)
+}
+
+func IsNotFound(err error) bool {
+	var nfErr *NotFoundError
+	if errors.As(err, &nfErr) {
+		return true
+	}
+
+	return getOpensearchErrorStatusCode(err) == 404
+}
+
+func IsConflict(err error) bool {
+	return getOpensearchErrorStatusCode(err) == 409
+}
+
+func IsForbidden(err error) bool {
+	return getOpensearchErrorStatusCode(err) == 403
+}
+
+func IsUnauthorized(err error) bool {
+	return getOpensearchErrorStatusCode(err) == 401
+}
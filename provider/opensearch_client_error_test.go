@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewOpensearchError(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		body           string
+		expectedType   string
+		expectedReason string
+		expectedStatus string
+		expectedMsg    string
+		expectedInErr  []string
+		absentFromErr  []string
+	}{
+		{
+			name:           "standard format",
+			status:         404,
+			body:           `{"error":{"type":"index_not_found_exception","reason":"no such index [logs]","root_cause":[{"type":"index_not_found_exception","reason":"no such index [logs]"}]},"status":404}`,
+			expectedType:   "index_not_found_exception",
+			expectedReason: "no such index [logs]",
+			expectedInErr:  []string{"returned code 404: index_not_found_exception: no such index [logs]"},
+			absentFromErr:  []string{"caused by"},
+		},
+		{
+			name:           "standard format with a different root cause",
+			status:         400,
+			body:           `{"error":{"type":"illegal_argument_exception","reason":"bad policy","root_cause":[{"type":"parse_exception","reason":"bad state"}]},"status":400}`,
+			expectedType:   "illegal_argument_exception",
+			expectedReason: "bad policy",
+			expectedInErr:  []string{"illegal_argument_exception: bad policy, caused by parse_exception: bad state"},
+		},
+		{
+			name:           "security plugin format",
+			status:         404,
+			body:           `{"status":"NOT_FOUND","message":"Resource 'demo' not found."}`,
+			expectedStatus: "NOT_FOUND",
+			expectedMsg:    "Resource 'demo' not found.",
+			expectedInErr:  []string{"returned code 404: Resource 'demo' not found."},
+		},
+		{
+			name:           "plain string error",
+			status:         400,
+			body:           `{"error":"Invalid index name","status":400}`,
+			expectedReason: "Invalid index name",
+			expectedInErr:  []string{"returned code 400: Invalid index name"},
+		},
+		{
+			name:          "body that is not json",
+			status:        502,
+			body:          `Bad Gateway`,
+			expectedInErr: []string{"returned code 502: Bad Gateway"},
+		},
+		{
+			name:           "unparsed body is redacted",
+			status:         400,
+			body:           `{"status":"BAD_REQUEST","details":{"password":"s3cr3t"}}`,
+			expectedStatus: "BAD_REQUEST",
+			expectedInErr:  []string{`"password":"\u003credacted\u003e"`},
+			absentFromErr:  []string{"s3cr3t"},
+		},
+		{
+			name:          "malformed json body is redacted",
+			status:        400,
+			body:          `{"status":"BAD_REQUEST","details":{"password":"s3cr3t", "hash": s3cr3t}`,
+			expectedInErr: []string{`"password":<redacted>, "hash": <redacted>}`},
+			absentFromErr: []string{"s3cr3t"},
+		},
+		{
+			name:          "form body is redacted",
+			status:        400,
+			body:          `Invalid request: username=demo&api_key=s3cr3t&token = s3cr3t`,
+			expectedInErr: []string{"username=demo&api_key=<redacted>&token = <redacted>"},
+			absentFromErr: []string{"s3cr3t"},
+		},
+		{
+			name:          "long body that is not json is truncated",
+			status:        502,
+			body:          "<html>" + strings.Repeat("a", 1000) + "</html>",
+			expectedInErr: []string{"<html>" + strings.Repeat("a", 506) + "... (truncated)"},
+			absentFromErr: []string{"</html>"},
+		},
+		{
+			name:           "forbidden hint",
+			status:         403,
+			body:           `{"status":"FORBIDDEN","message":"no permissions"}`,
+			expectedStatus: "FORBIDDEN",
+			expectedMsg:    "no permissions",
+			expectedInErr:  []string{"restapi:admin/roles"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "http://localhost:9200/_plugins/_security/api/roles/demo", nil)
+			res := &http.Response{StatusCode: tt.status}
+
+			osErr := NewOpensearchError("http://localhost:9200", req, res, tt.body)
+			if osErr.Type != tt.expectedType {
+				t.Errorf("Expected type '%s', got '%s'", tt.expectedType, osErr.Type)
+			}
+			if osErr.Reason != tt.expectedReason {
+				t.Errorf("Expected reason '%s', got '%s'", tt.expectedReason, osErr.Reason)
+			}
+			if osErr.SecurityStatus != tt.expectedStatus {
+				t.Errorf("Expected security status '%s', got '%s'", tt.expectedStatus, osErr.SecurityStatus)
+			}
+			if osErr.Message != tt.expectedMsg {
+				t.Errorf("Expected message '%s', got '%s'", tt.expectedMsg, osErr.Message)
+			}
+
+			errMsg := osErr.Error()
+			for _, expected := range tt.expectedInErr {
+				if !strings.Contains(errMsg, expected) {
+					t.Errorf("Expected '%s' in error '%s'", expected, errMsg)
+				}
+			}
+			for _, absent := range tt.absentFromErr {
+				if strings.Contains(errMsg, absent) {
+					t.Errorf("Did not expect '%s' in error '%s'", absent, errMsg)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://localhost:9200/_plugins/_ism/policies/demo", nil)
+	tests := []struct {
+		name         string
+		err          error
+		notFound     bool
+		conflict     bool
+		forbidden    bool
+		unauthorized bool
+	}{
+		{name: "404", err: NewOpensearchError("", req, &http.Response{StatusCode: 404}, ""), notFound: true},
+		{name: "409", err: NewOpensearchError("", req, &http.Response{StatusCode: 409}, ""), conflict: true},
+		{name: "403", err: NewOpensearchError("", req, &http.Response{StatusCode: 403}, ""), forbidden: true},
+		{name: "401", err: NewOpensearchError("", req, &http.Response{StatusCode: 401}, ""), unauthorized: true},
+		{name: "missing object", err: &NotFoundError{Kind: "role", Name: "demo"}, notFound: true},
+		{name: "nil", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if IsNotFound(tt.err) != tt.notFound {
+				t.Errorf("Expected IsNotFound to be %t", tt.notFound)
+			}
+			if IsConflict(tt.err) != tt.conflict {
+				t.Errorf("Expected IsConflict to be %t", tt.conflict)
+			}
+			if IsForbidden(tt.err) != tt.forbidden {
+				t.Errorf("Expected IsForbidden to be %t", tt.forbidden)
+			}
+			if IsUnauthorized(tt.err) != tt.unauthorized {
+				t.Errorf("Expected IsUnauthorized to be %t", tt.unauthorized)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+type IsmExplainStateModel struct {
+	Name      string `json:"name"`
+	StartTime int64  `json:"start_time"`
+}
+
+type IsmExplainActionModel struct {
+	Name            string `json:"name"`
+	StartTime       int64  `json:"start_time"`
+	Index           int64  `json:"index"`
+	Failed          bool   `json:"failed"`
+	ConsumedRetries int64  `json:"consumed_retries"`
+	LastRetryTime   int64  `json:"last_retry_time"`
+}
+
+type IsmExplainStepModel struct {
+	Name       string `json:"name"`
+	StartTime  int64  `json:"start_time"`
+	StepStatus string `json:"step_status"`
+}
+
+type IsmExplainRetryInfoModel struct {
+	Failed          bool  `json:"failed"`
+	ConsumedRetries int64 `json:"consumed_retries"`
+}
+
+//Indices that are not managed are also listed when they are explicitly requested, without a policy id
+type IsmExplainIndexModel struct {
+	Index              string                    `json:"index"`
+	IndexUuid          string                    `json:"index_uuid"`
+	PolicyId           string                    `json:"policy_id"`
+	PluginsPolicyId    string                    `json:"index.plugins.index_state_management.policy_id"`
+	OpendistroPolicyId string                    `json:"index.opendistro.index_state_management.policy_id"`
+	Enabled            *bool                     `json:"enabled"`
+	State              *IsmExplainStateModel     `json:"state"`
+	Action             *IsmExplainActionModel    `json:"action"`
+	Step               *IsmExplainStepModel      `json:"step"`
+	RetryInfo          *IsmExplainRetryInfoModel `json:"retry_info"`
+	Info               map[string]interface{}    `json:"info"`
+}
+
+//The policy id is reported under different keys depending on the distribution and on whether the index was initialized by ism yet
+func (explain *IsmExplainIndexModel) GetPolicyId() string {
+	for _, policyId := range []string{(*explain).PolicyId, (*explain).PluginsPolicyId, (*explain).OpendistroPolicyId} {
+		if policyId != "" {
+			return policyId
+		}
+	}
+
+	return ""
+}
+
+func (explain *IsmExplainIndexModel) IsManaged() bool {
+	return explain.GetPolicyId() != ""
+}
+
+//An index is stuck in a failed step until the step is retried, either automatically or through the retry api
+func (explain *IsmExplainIndexModel) IsFailed() bool {
+	if (*explain).RetryInfo != nil && (*explain).RetryInfo.Failed {
+		return true
+	}
+
+	if (*explain).Action != nil && (*explain).Action.Failed {
+		return true
+	}
+
+	return (*explain).Step != nil && (*explain).Step.StepStatus == "failed"
+}
+
+func (explain *IsmExplainIndexModel) GetInfoMessage() string {
+	message, _ := (*explain).Info["message"].(string)
+	return message
+}
+
+//Number of indices requested per page when explaining all the managed indices
+const ismExplainPageSize = 100
+
+//The explanations are keyed by index name, alongside the total number of managed indices
+func parseIsmExplainResponse(b []byte) (map[string]IsmExplainIndexModel, int64, error) {
+	var fields map[string]json.RawMessage
+	uErr := json.Unmarshal(b, &fields)
+	if uErr != nil {
+		return nil, 0, uErr
+	}
+
+	total := int64(0)
+	explanations := make(map[string]IsmExplainIndexModel)
+	for key, val := range fields {
+		if key == "total_managed_indices" {
+			uErr = json.Unmarshal(val, &total)
+			if uErr != nil {
+				return nil, 0, uErr
+			}
+			continue
+		}
+
+		explanation := IsmExplainIndexModel{}
+		uErr = json.Unmarshal(val, &explanation)
+		if uErr != nil {
+			return nil, 0, uErr
+		}
+		if explanation.Index == "" {
+			explanation.Index = key
+		}
+		explanations[key] = explanation
+	}
+
+	return explanations, total, nil
+}
+
+func (reqCon *RequestContext) explainIsm(urlPath string, queryString string) (map[string]IsmExplainIndexModel, int64, error) {
+	pluginErr := (*reqCon).Client.RequirePlugin(IsmPlugin)
+	if pluginErr != nil {
+		return nil, 0, pluginErr
+	}
+
+	res, err := reqCon.Do(
+		"GET",
+		urlPath,
+		queryString,
+		"",
+		[]int64{},
+	)
+
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	b, bErr := ioutil.ReadAll(res.Body)
+	if bErr != nil {
+		return nil, 0, bErr
+	}
+
+	return parseIsmExplainResponse(b)
+}
+
+//Explains the ism status of the indices matching an index expression (comma separated names, wildcards)
+func (reqCon *RequestContext) ExplainIsmIndices(index string) (map[string]IsmExplainIndexModel, error) {
+	explanations, _, err := reqCon.explainIsm(
+		(*reqCon).Client.GetPluginApiPath(IsmPlugin, "explain", index),
+		"",
+	)
+
+	return explanations, err
+}
+
+//Explains all the indices managed by ism, going through all the pages of the results
+func (reqCon *RequestContext) ExplainAllIsmIndices() (map[string]IsmExplainIndexModel, error) {
+	explanations := make(map[string]IsmExplainIndexModel)
+	for from := 0; ; from += ismExplainPageSize {
+		page, total, err := reqCon.explainIsm(
+			(*reqCon).Client.GetPluginApiPath(IsmPlugin, "explain"),
+			fmt.Sprintf("from=%d&size=%d", from, ismExplainPageSize),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for index, explanation := range page {
+			explanations[index] = explanation
+		}
+
+		if len(page) < ismExplainPageSize || int64(from + ismExplainPageSize) >= total {
+			return explanations, nil
+		}
+	}
+}
+
+func (reqCon *RequestContext) GetIsmPolicyManagedIndices(policyId string) ([]string, error) {
+	explanations, err := reqCon.ExplainAllIsmIndices()
+	if err != nil {
+		return nil, err
+	}
+
+	indices := []string{}
+	for index, explanation := range explanations {
+		if explanation.GetPolicyId() == policyId {
+			indices = append(indices, index)
+		}
+	}
+
+	return indices, nil
+}
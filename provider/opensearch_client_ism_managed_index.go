@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+type IsmStateFilterModel struct {
+	State string `json:"state"`
+}
+
+//Switches the managed indices to the latest version of a policy, or to another policy.
+//The switch happens once the indices have completed their current state, unless a state to move to is given.
+type IsmChangePolicyModel struct {
+	PolicyId string                `json:"policy_id"`
+	State    string                `json:"state,omitempty"`
+	Include  []IsmStateFilterModel `json:"include,omitempty"`
+}
+
+type IsmFailedIndexModel struct {
+	IndexName string `json:"index_name"`
+	IndexUuid string `json:"index_uuid"`
+	Reason    string `json:"reason"`
+}
+
+type IsmManagedIndicesUpdateModel struct {
+	UpdatedIndices int64                 `json:"updated_indices"`
+	Failures       bool                  `json:"failures"`
+	FailedIndices  []IsmFailedIndexModel `json:"failed_indices"`
+}
+
+//Adds up the results of operations performed on the indices in several batches
+func (update *IsmManagedIndicesUpdateModel) Merge(other *IsmManagedIndicesUpdateModel) {
+	(*update).UpdatedIndices += other.UpdatedIndices
+	(*update).Failures = (*update).Failures || other.Failures
+	(*update).FailedIndices = append((*update).FailedIndices, other.FailedIndices...)
+}
+
+func (update *IsmManagedIndicesUpdateModel) GetFailuresError() error {
+	if len((*update).FailedIndices) == 0 {
+		return nil
+	}
+
+	failures := []string{}
+	for _, failed := range (*update).FailedIndices {
+		failures = append(failures, fmt.Sprintf("%s (%s)", failed.IndexName, failed.Reason))
+	}
+
+	return errors.New(fmt.Sprintf("%d indices failed: %s", len(failures), strings.Join(failures, ", ")))
+}
+
+//Number of indices listed in the path of a single request, to keep urls short
+const ismManagedIndicesBatchSize = 50
+
+type IsmIndicesBatchUpdate func(reqCon *RequestContext, index string) (*IsmManagedIndicesUpdateModel, error)
+
+//Performs an operation on a list of indices in batches, each with its own request context
+func (cli *OpensearchClient) UpdateIsmIndicesInBatches(ctx context.Context, indices []string, updateBatch IsmIndicesBatchUpdate) (*IsmManagedIndicesUpdateModel, error) {
+	update := IsmManagedIndicesUpdateModel{
+		FailedIndices: []IsmFailedIndexModel{},
+	}
+
+	for start := 0; start < len(indices); start += ismManagedIndicesBatchSize {
+		end := start + ismManagedIndicesBatchSize
+		if end > len(indices) {
+			end = len(indices)
+		}
+
+		batchUpdate, err := updateBatch(cli.GetRequestContext(ctx), strings.Join(indices[start:end], ","))
+		if err != nil {
+			return nil, err
+		}
+		update.Merge(batchUpdate)
+	}
+
+	return &update, nil
+}
+
+func (reqCon *RequestContext) updateIsmIndices(urlPath string, body string) (*IsmManagedIndicesUpdateModel, error) {
+	pluginErr := (*reqCon).Client.RequirePlugin(IsmPlugin)
+	if pluginErr != nil {
+		return nil, pluginErr
+	}
+
+	res, err := reqCon.Do(
+		"POST",
+		urlPath,
+		"",
+		body,
+		[]int64{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, bErr := ioutil.ReadAll(res.Body)
+	if bErr != nil {
+		return nil, bErr
+	}
+
+	update := IsmManagedIndicesUpdateModel{
+		FailedIndices: []IsmFailedIndexModel{},
+	}
+	uErr := json.Unmarshal(b, &update)
+	if uErr != nil {
+		return nil, uErr
+	}
+
+	return &update, nil
+}
+
+type IsmAddPolicyModel struct {
+	PolicyId string `json:"policy_id"`
+}
+
+//Indices that are already managed by a policy are reported as failures
+func (reqCon *RequestContext) AddIsmPolicy(index string, policyId string) (*IsmManagedIndicesUpdateModel, error) {
+	addStr, marErr := json.Marshal(IsmAddPolicyModel{PolicyId: policyId})
+	if marErr != nil {
+		return nil, marErr
+	}
+
+	return reqCon.updateIsmIndices(
+		(*reqCon).Client.GetPluginApiPath(IsmPlugin, "add", index),
+		string(addStr),
+	)
+}
+
+func (reqCon *RequestContext) RemoveIsmPolicy(index string) (*IsmManagedIndicesUpdateModel, error) {
+	return reqCon.updateIsmIndices(
+		(*reqCon).Client.GetPluginApiPath(IsmPlugin, "remove", index),
+		"",
+	)
+}
+
+func (reqCon *RequestContext) ChangeIsmPolicy(index string, change IsmChangePolicyModel) (*IsmManagedIndicesUpdateModel, error) {
+	changeStr, marErr := json.Marshal(change)
+	if marErr != nil {
+		return nil, marErr
+	}
+
+	return reqCon.updateIsmIndices(
+		(*reqCon).Client.GetPluginApiPath(IsmPlugin, "change_policy", index),
+		string(changeStr),
+	)
+}
+
+//Retries the failed step of the indices, optionally from the start of another state.
+//Indices that are not in a failed step are reported as failures.
+type IsmRetryModel struct {
+	State string `json:"state,omitempty"`
+}
+
+func (reqCon *RequestContext) RetryIsm(index string, retry IsmRetryModel) (*IsmManagedIndicesUpdateModel, error) {
+	retryStr, marErr := json.Marshal(retry)
+	if marErr != nil {
+		return nil, marErr
+	}
+
+	return reqCon.updateIsmIndices(
+		(*reqCon).Client.GetPluginApiPath(IsmPlugin, "retry", index),
+		string(retryStr),
+	)
+}
+
+func (cli *OpensearchClient) ChangeIsmPolicyOfIndices(ctx context.Context, indices []string, change IsmChangePolicyModel) (*IsmManagedIndicesUpdateModel, error) {
+	return cli.UpdateIsmIndicesInBatches(ctx, indices, func(reqCon *RequestContext, index string) (*IsmManagedIndicesUpdateModel, error) {
+		return reqCon.ChangeIsmPolicy(index, change)
+	})
+}
+
+func (cli *OpensearchClient) AddIsmPolicyToIndices(ctx context.Context, indices []string, policyId string) (*IsmManagedIndicesUpdateModel, error) {
+	return cli.UpdateIsmIndicesInBatches(ctx, indices, func(reqCon *RequestContext, index string) (*IsmManagedIndicesUpdateModel, error) {
+		return reqCon.AddIsmPolicy(index, policyId)
+	})
+}
+
+func (cli *OpensearchClient) RemoveIsmPolicyFromIndices(ctx context.Context, indices []string) (*IsmManagedIndicesUpdateModel, error) {
+	return cli.UpdateIsmIndicesInBatches(ctx, indices, func(reqCon *RequestContext, index string) (*IsmManagedIndicesUpdateModel, error) {
+		return reqCon.RemoveIsmPolicy(index)
+	})
+}
+
+func (cli *OpensearchClient) RetryIsmOfIndices(ctx context.Context, indices []string, retry IsmRetryModel) (*IsmManagedIndicesUpdateModel, error) {
+	return cli.UpdateIsmIndicesInBatches(ctx, indices, func(reqCon *RequestContext, index string) (*IsmManagedIndicesUpdateModel, error) {
+		return reqCon.RetryIsm(index, retry)
+	})
+}
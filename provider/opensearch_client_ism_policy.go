@@ -2,9 +2,9 @@ package provider
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"path"
 )
 
 type EmptyModel struct {}
@@ -17,6 +17,143 @@ type IsmPsaIndexPriorityModel struct {
 	IndexPriority int64 `json:"priority"`
 }
 
+type IsmPsaRolloverModel struct {
+	MinSize             string `json:"min_size,omitempty"`
+	MinPrimaryShardSize string `json:"min_primary_shard_size,omitempty"`
+	MinDocCount         *int64 `json:"min_doc_count,omitempty"`
+	MinIndexAge         string `json:"min_index_age,omitempty"`
+	CopyAlias           bool   `json:"copy_alias,omitempty"`
+}
+
+func (r *IsmPsaRolloverModel) IsEmpty() bool {
+	return r.MinSize == "" && r.MinPrimaryShardSize == "" && r.MinDocCount == nil && r.MinIndexAge == "" && !r.CopyAlias
+}
+
+type IsmPsaForceMergeModel struct {
+	MaxNumSegments int64 `json:"max_num_segments"`
+}
+
+type IsmScriptModel struct {
+	Source string `json:"source"`
+	Lang   string `json:"lang,omitempty"`
+}
+
+type IsmPsaShrinkModel struct {
+	NumNewShards             int64           `json:"num_new_shards,omitempty"`
+	MaxShardSize             string          `json:"max_shard_size,omitempty"`
+	PercentageOfSourceShards float64         `json:"percentage_of_source_shards,omitempty"`
+	TargetIndexNameTemplate  *IsmScriptModel `json:"target_index_name_template,omitempty"`
+	ForceUnsafe              bool            `json:"force_unsafe,omitempty"`
+}
+
+//The api supports arbitrary mustache templates for the name of the shrunken index,
+//but the provider only exposes a suffix to append to the source index name
+const shrinkTargetIndexNamePrefix = "{{ctx.index}}"
+
+func (s *IsmPsaShrinkModel) GetSizingOptionsCount() int {
+	count := 0
+	if s.NumNewShards > 0 {
+		count += 1
+	}
+	if s.MaxShardSize != "" {
+		count += 1
+	}
+	if s.PercentageOfSourceShards > 0 {
+		count += 1
+	}
+	return count
+}
+
+type IsmPsaAllocationModel struct {
+	Require map[string]string `json:"require,omitempty"`
+	Include map[string]string `json:"include,omitempty"`
+	Exclude map[string]string `json:"exclude,omitempty"`
+	WaitFor bool              `json:"wait_for,omitempty"`
+}
+
+type IsmPsaSnapshotModel struct {
+	Repository string `json:"repository"`
+	Snapshot   string `json:"snapshot"`
+}
+
+type IsmDateHistogramModel struct {
+	SourceField      string `json:"source_field"`
+	TargetField      string `json:"target_field,omitempty"`
+	FixedInterval    string `json:"fixed_interval,omitempty"`
+	CalendarInterval string `json:"calendar_interval,omitempty"`
+	Timezone         string `json:"timezone,omitempty"`
+}
+
+type IsmTermsModel struct {
+	SourceField string `json:"source_field"`
+	TargetField string `json:"target_field,omitempty"`
+}
+
+type IsmHistogramModel struct {
+	SourceField string  `json:"source_field"`
+	TargetField string  `json:"target_field,omitempty"`
+	Interval    float64 `json:"interval"`
+}
+
+//Dimensions of rollups and groups of transforms share the same format, with exactly one of the fields set
+type IsmDimensionModel struct {
+	DateHistogram *IsmDateHistogramModel `json:"date_histogram,omitempty"`
+	Terms         *IsmTermsModel         `json:"terms,omitempty"`
+	Histogram     *IsmHistogramModel     `json:"histogram,omitempty"`
+}
+
+type IsmRollupMetricModel struct {
+	SourceField string                  `json:"source_field"`
+	Metrics     []map[string]EmptyModel `json:"metrics"`
+}
+
+type IsmRollupModel struct {
+	Description string                 `json:"description"`
+	TargetIndex string                 `json:"target_index"`
+	PageSize    int64                  `json:"page_size"`
+	Dimensions  []IsmDimensionModel    `json:"dimensions"`
+	Metrics     []IsmRollupMetricModel `json:"metrics"`
+}
+
+type IsmPsaRollupModel struct {
+	IsmRollup IsmRollupModel `json:"ism_rollup"`
+}
+
+type IsmTransformModel struct {
+	Description        string              `json:"description"`
+	TargetIndex        string              `json:"transform_target_index"`
+	PageSize           int64               `json:"page_size"`
+	DataSelectionQuery json.RawMessage     `json:"data_selection_query,omitempty"`
+	Groups             []IsmDimensionModel `json:"groups"`
+	Aggregations       json.RawMessage     `json:"aggregations,omitempty"`
+}
+
+type IsmPsaTransformModel struct {
+	IsmTransform IsmTransformModel `json:"ism_transform"`
+}
+
+type IsmChannelModel struct {
+	Id string `json:"id"`
+}
+
+type IsmWebhookModel struct {
+	Url string `json:"url"`
+}
+
+//Legacy destinations, from before the notifications plugin
+type IsmDestinationModel struct {
+	Slack         *IsmWebhookModel `json:"slack,omitempty"`
+	Chime         *IsmWebhookModel `json:"chime,omitempty"`
+	CustomWebhook *IsmWebhookModel `json:"custom_webhook,omitempty"`
+}
+
+//Exactly one of the channel and the destination should be set
+type IsmNotificationModel struct {
+	Channel         *IsmChannelModel     `json:"channel,omitempty"`
+	Destination     *IsmDestinationModel `json:"destination,omitempty"`
+	MessageTemplate IsmScriptModel       `json:"message_template"`
+}
+
 type IsmPsaRetryModel struct {
 	Count   int64  `json:"count"`
 	Backoff string `json:"backoff,omitempty"`
@@ -37,17 +174,37 @@ type IsmPsActionModel struct {
 	Delete        *EmptyModel                `json:"delete,omitempty"`
 	ReplicaCount  *IsmPsaReplicaCountModel   `json:"replica_count,omitempty"`
 	IndexPriority *IsmPsaIndexPriorityModel  `json:"index_priority,omitempty"`
+	Rollover      *IsmPsaRolloverModel       `json:"rollover,omitempty"`
+	ForceMerge    *IsmPsaForceMergeModel     `json:"force_merge,omitempty"`
+	Shrink        *IsmPsaShrinkModel         `json:"shrink,omitempty"`
+	Allocation    *IsmPsaAllocationModel     `json:"allocation,omitempty"`
+	Snapshot      *IsmPsaSnapshotModel       `json:"snapshot,omitempty"`
+	Rollup        *IsmPsaRollupModel         `json:"rollup,omitempty"`
+	Transform     *IsmPsaTransformModel      `json:"transform,omitempty"`
+	Notification  *IsmNotificationModel      `json:"notification,omitempty"`
+}
+
+type IsmCronExpressionModel struct {
+	Expression string `json:"expression"`
+	Timezone   string `json:"timezone"`
+}
+
+type IsmCronModel struct {
+	Cron IsmCronExpressionModel `json:"cron"`
 }
 
 type IsmPstConditionModel struct {
-	MinIndexAge string `json:"min_index_age,omitempty"`
-	MinDocCount int64  `json:"min_doc_count,omitempty"`
-	MinSize     string `json:"min_size,omitempty"`
+	MinIndexAge    string        `json:"min_index_age,omitempty"`
+	MinRolloverAge string        `json:"min_rollover_age,omitempty"`
+	MinDocCount    *int64        `json:"min_doc_count,omitempty"`
+	MinSize        string        `json:"min_size,omitempty"`
+	Cron           *IsmCronModel `json:"cron,omitempty"`
 }
 
+//Transitions without conditions happen as soon as the actions of the state are completed
 type IsmPsTransitionModel struct {
 	StateName  string					`json:"state_name"`
-	Conditions IsmPstConditionModel	    `json:"conditions"`
+	Conditions *IsmPstConditionModel	`json:"conditions,omitempty"`
 }
 
 type IsmPolicyStateModel struct {
@@ -60,7 +217,7 @@ type IsmPolicyStateModel struct {
 //This could cause constant updates that do nothing on terraform apply.
 //This function helps trim default retries value that map to an absent retry in the terraform state
 func (s *IsmPolicyStateModel) GetDefaultRetriesAdjustedActions(next *IsmPolicyStateModel) []IsmPsActionModel {
-	if len(s.Actions) != len(next.Actions) {
+	if s == nil || len(s.Actions) != len(next.Actions) {
 		return next.Actions
 	}
 
@@ -68,7 +225,7 @@ func (s *IsmPolicyStateModel) GetDefaultRetriesAdjustedActions(next *IsmPolicySt
 	for idx, _ := range s.Actions {
 		currAction := s.Actions[idx]
 		nextAction := next.Actions[idx]
-		if currAction.Retry == nil && nextAction.Retry.IsDefault() {
+		if currAction.Retry == nil && nextAction.Retry != nil && nextAction.Retry.IsDefault() {
 			nextAction.Retry = nil
 		}
 
@@ -78,17 +235,43 @@ func (s *IsmPolicyStateModel) GetDefaultRetriesAdjustedActions(next *IsmPolicySt
 	return trimmedActions
 }
 
+//Opensearch api returns the query and aggregations of transforms with all their default parameters filled in.
+//These would never match the json passed in the terraform state so the previous values are kept instead.
+//As a result, changes made to these fields outside of terraform are not detected.
+func (s *IsmPolicyStateModel) GetTransformJsonAdjustedActions(next []IsmPsActionModel) []IsmPsActionModel {
+	if s == nil || len(s.Actions) != len(next) {
+		return next
+	}
+
+	adjustedActions := make([]IsmPsActionModel, len(next))
+	for idx, _ := range next {
+		currAction := s.Actions[idx]
+		nextAction := next[idx]
+		if currAction.Transform != nil && nextAction.Transform != nil {
+			transform := *nextAction.Transform
+			transform.IsmTransform.DataSelectionQuery = currAction.Transform.IsmTransform.DataSelectionQuery
+			transform.IsmTransform.Aggregations = currAction.Transform.IsmTransform.Aggregations
+			nextAction.Transform = &transform
+		}
+
+		adjustedActions[idx] = nextAction
+	}
+
+	return adjustedActions
+}
+
 type IsmTemplateModel struct {
 	Priority      *int64	`json:"priority,omitempty"`
 	IndexPatterns []string	`json:"index_patterns"`
 } 
 
 type IsmPolicyModel struct {
-	PolicyId     string                  `json:"-"`
-	Description  string                  `json:"description"`
-	IsmTemplate  []IsmTemplateModel      `json:"ism_template,omitempty"`
-	DefaultState string                  `json:"default_state"`
-	States       []IsmPolicyStateModel	 `json:"states"`
+	PolicyId          string                  `json:"-"`
+	Description       string                  `json:"description"`
+	IsmTemplate       []IsmTemplateModel      `json:"ism_template,omitempty"`
+	DefaultState      string                  `json:"default_state"`
+	States            []IsmPolicyStateModel   `json:"states"`
+	ErrorNotification *IsmNotificationModel   `json:"error_notification,omitempty"`
 }
 
 func (p *IsmPolicyModel) GetStateNamed(name string) *IsmPolicyStateModel {
@@ -101,29 +284,105 @@ func (p *IsmPolicyModel) GetStateNamed(name string) *IsmPolicyStateModel {
 	return nil
 }
 
+//Returns an error for inconsistencies of the states that opensearch would reject or that would leave indices stuck,
+//and warnings for the states that no index can reach by going through the policy
+func (p *IsmPolicyModel) ValidateGraph() ([]string, error) {
+	return p.ValidatePartialGraph(IsmPolicyGraphUnknowns{})
+}
+
+//Parts of the policy that are not known yet, in which case the states and transitions they affect are left out of the model
+type IsmPolicyGraphUnknowns struct {
+	StateNames   bool
+	DefaultState bool
+	Transitions  bool
+}
+
+//Same as ValidateGraph, but skips the checks that the unknown parts of the policy could invalidate
+func (p *IsmPolicyModel) ValidatePartialGraph(unknowns IsmPolicyGraphUnknowns) ([]string, error) {
+	stateIdxs := make(map[string]int)
+	for idx, state := range p.States {
+		_, duplicate := stateIdxs[state.Name]
+		if duplicate {
+			return nil, errors.New(fmt.Sprintf("State '%s' is defined more than once", state.Name))
+		}
+		stateIdxs[state.Name] = idx
+	}
+
+	_, defaultStateExists := stateIdxs[p.DefaultState]
+	if !defaultStateExists && !unknowns.StateNames && !unknowns.DefaultState {
+		return nil, errors.New(fmt.Sprintf("The default state '%s' is not defined", p.DefaultState))
+	}
+
+	for _, state := range p.States {
+		for idx, action := range state.Actions {
+			if action.Delete != nil && idx != len(state.Actions) - 1 {
+				return nil, errors.New(fmt.Sprintf("Action %d of state '%s': the delete action must be the last action of the state", idx + 1, state.Name))
+			}
+		}
+
+		for idx, transition := range state.Transitions {
+			_, targetExists := stateIdxs[transition.StateName]
+			if !targetExists && !unknowns.StateNames {
+				return nil, errors.New(fmt.Sprintf("Transition %d of state '%s': the state '%s' is not defined", idx + 1, state.Name, transition.StateName))
+			}
+		}
+	}
+
+	if unknowns.StateNames || unknowns.DefaultState || unknowns.Transitions {
+		return []string{}, nil
+	}
+
+	reached := map[string]bool{p.DefaultState: true}
+	toVisit := []string{p.DefaultState}
+	for len(toVisit) > 0 {
+		state := p.States[stateIdxs[toVisit[0]]]
+		toVisit = toVisit[1:]
+		for _, transition := range state.Transitions {
+			if !reached[transition.StateName] {
+				reached[transition.StateName] = true
+				toVisit = append(toVisit, transition.StateName)
+			}
+		}
+	}
+
+	warnings := []string{}
+	for _, state := range p.States {
+		if !reached[state.Name] {
+			warnings = append(warnings, fmt.Sprintf("State '%s' cannot be reached from the default state '%s' and will only be used by indices explicitly moved to it", state.Name, p.DefaultState))
+		}
+	}
+
+	return warnings, nil
+}
+
 type IsmPolicyUpdateInfoModel struct {
 	PrimaryTerm int64 `json:"_primary_term"`
 	SeqNo       int64 `json:"_seq_no"`
 }
 
 func (reqCon *RequestContext) GetIsmPolicyUpdateInfo(policyId string) (*IsmPolicyUpdateInfoModel, error) {
+	pluginErr := (*reqCon).Client.RequirePlugin(IsmPlugin)
+	if pluginErr != nil {
+		return nil, pluginErr
+	}
+
 	res, err := reqCon.Do(
 		"GET", 
-		path.Join("_plugins/_ism/policies/", policyId),
+		(*reqCon).Client.GetPluginApiPath(IsmPlugin, "policies", policyId),
 		"",
 		"",
-		[]int64{404},
+		[]int64{},
 	)
+
+	if IsNotFound(err) {
+		return nil, nil
+	}
 	
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode == 404 {
-		return nil, nil
-	}
-
 	b, bErr := ioutil.ReadAll(res.Body)
 	if bErr != nil {
 		return nil, bErr
@@ -138,15 +397,30 @@ func (reqCon *RequestContext) GetIsmPolicyUpdateInfo(policyId string) (*IsmPolic
 	return &updateInfo, nil
 }
 
-func (reqCon *RequestContext) UpsertIsmPolicy(ismPolicy IsmPolicyModel) error {	
-	ismPolicyMap := make(map[string]IsmPolicyModel)
-	ismPolicyMap["policy"] = ismPolicy
+func (reqCon *RequestContext) UpsertIsmPolicy(ismPolicy IsmPolicyModel) error {
+	ismPolicyStr, marErr := json.Marshal(ismPolicy)
+    if marErr != nil {
+        return marErr
+    }
+
+	return reqCon.UpsertIsmPolicyJson(ismPolicy.PolicyId, string(ismPolicyStr))
+}
+
+//Upserts a policy from its json document, without the policy wrapper the api expects
+func (reqCon *RequestContext) UpsertIsmPolicyJson(policyId string, policyJson string) error {
+	pluginErr := (*reqCon).Client.RequirePlugin(IsmPlugin)
+	if pluginErr != nil {
+		return pluginErr
+	}
+	
+	ismPolicyMap := make(map[string]json.RawMessage)
+	ismPolicyMap["policy"] = json.RawMessage(policyJson)
 	ismPolicyStr, marErr := json.Marshal(ismPolicyMap)
     if marErr != nil {
         return marErr
     }
 
-	info, infoErr := reqCon.GetIsmPolicyUpdateInfo(ismPolicy.PolicyId)
+	info, infoErr := reqCon.GetIsmPolicyUpdateInfo(policyId)
 	if infoErr != nil {
 		return infoErr
 	}
@@ -158,12 +432,21 @@ func (reqCon *RequestContext) UpsertIsmPolicy(ismPolicy IsmPolicyModel) error {
 
 	res, err := reqCon.Do(
 		"PUT", 
-		path.Join("_plugins/_ism/policies/", ismPolicy.PolicyId),
+		(*reqCon).Client.GetPluginApiPath(IsmPlugin, "policies", policyId),
 		queryString,
 		string(ismPolicyStr),
 		[]int64{},
 	)
 
+	//The policy was modified between the retrieval of its sequence number and the update.
+	//Re-sending the same request would conflict again so the sequence number is refreshed beforehand.
+	if IsConflict(err) && (*reqCon).RetriesLeft > 0 {
+		if reqCon.WaitForRetry(res) != nil {
+			return err
+		}
+		return reqCon.UpsertIsmPolicyJson(policyId, policyJson)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -173,13 +456,19 @@ func (reqCon *RequestContext) UpsertIsmPolicy(ismPolicy IsmPolicyModel) error {
 }
 
 type IsmPolicyGetModel struct {
-	Policy IsmPolicyModel `json:"policy"`
+	Policy json.RawMessage `json:"policy"`
 }
 
-func (reqCon *RequestContext) GetIsmPolicy(policyId string) (*IsmPolicyModel, error) {
+//Returns the json document of the policy, as stored by opensearch
+func (reqCon *RequestContext) GetIsmPolicyJson(policyId string) (json.RawMessage, error) {
+	pluginErr := (*reqCon).Client.RequirePlugin(IsmPlugin)
+	if pluginErr != nil {
+		return nil, pluginErr
+	}
+
 	res, err := reqCon.Do(
 		"GET", 
-		path.Join("_plugins/_ism/policies/", policyId),
+		(*reqCon).Client.GetPluginApiPath(IsmPlugin, "policies", policyId),
 		"",
 		"",
 		[]int64{},
@@ -200,16 +489,39 @@ func (reqCon *RequestContext) GetIsmPolicy(policyId string) (*IsmPolicyModel, er
 	if uErr != nil {
 		return nil, uErr
 	}
+
+	if len(policyGet.Policy) == 0 || string(policyGet.Policy) == "null" {
+		return nil, &NotFoundError{Kind: "policy", Name: policyId}
+	}
+	
+	return policyGet.Policy, nil
+}
+
+func (reqCon *RequestContext) GetIsmPolicy(policyId string) (*IsmPolicyModel, error) {
+	policyJson, err := reqCon.GetIsmPolicyJson(policyId)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy IsmPolicyModel
+	uErr := json.Unmarshal(policyJson, &policy)
+	if uErr != nil {
+		return nil, uErr
+	}
 	
-	policy := policyGet.Policy
 	policy.PolicyId = policyId
 	return &policy, nil
 }
 
 func (reqCon *RequestContext) DeleteIsmPolicy(policyId string) error {
+	pluginErr := (*reqCon).Client.RequirePlugin(IsmPlugin)
+	if pluginErr != nil {
+		return pluginErr
+	}
+
 	res, err := reqCon.Do(
 		"DELETE", 
-		path.Join("_plugins/_ism/policies/", policyId),
+		(*reqCon).Client.GetPluginApiPath(IsmPlugin, "policies", policyId),
 		"",
 		"",
 		[]int64{},
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsmPolicyValidateGraph(t *testing.T) {
+	transition := func(target string) IsmPsTransitionModel {
+		return IsmPsTransitionModel{StateName: target}
+	}
+
+	tests := []struct {
+		name             string
+		policy           IsmPolicyModel
+		expectedErr      string
+		expectedWarnings []string
+	}{
+		{
+			name: "valid policy",
+			policy: IsmPolicyModel{
+				DefaultState: "hot",
+				States: []IsmPolicyStateModel{
+					{Name: "hot", Transitions: []IsmPsTransitionModel{transition("warm")}},
+					{Name: "warm", Transitions: []IsmPsTransitionModel{transition("delete")}},
+					{Name: "delete", Actions: []IsmPsActionModel{{ReadOnly: &EmptyModel{}}, {Delete: &EmptyModel{}}}},
+				},
+			},
+		},
+		{
+			name: "cycle between states",
+			policy: IsmPolicyModel{
+				DefaultState: "a",
+				States: []IsmPolicyStateModel{
+					{Name: "a", Transitions: []IsmPsTransitionModel{transition("b")}},
+					{Name: "b", Transitions: []IsmPsTransitionModel{transition("a")}},
+				},
+			},
+		},
+		{
+			name: "duplicate state",
+			policy: IsmPolicyModel{
+				DefaultState: "hot",
+				States:       []IsmPolicyStateModel{{Name: "hot"}, {Name: "hot"}},
+			},
+			expectedErr: "State 'hot' is defined more than once",
+		},
+		{
+			name: "missing default state",
+			policy: IsmPolicyModel{
+				DefaultState: "cold",
+				States:       []IsmPolicyStateModel{{Name: "hot"}},
+			},
+			expectedErr: "The default state 'cold' is not defined",
+		},
+		{
+			name: "delete before other actions",
+			policy: IsmPolicyModel{
+				DefaultState: "hot",
+				States: []IsmPolicyStateModel{
+					{Name: "hot", Actions: []IsmPsActionModel{{Delete: &EmptyModel{}}, {ReadOnly: &EmptyModel{}}}},
+				},
+			},
+			expectedErr: "the delete action must be the last action of the state",
+		},
+		{
+			name: "transition to a missing state",
+			policy: IsmPolicyModel{
+				DefaultState: "hot",
+				States: []IsmPolicyStateModel{
+					{Name: "hot", Transitions: []IsmPsTransitionModel{transition("cold")}},
+				},
+			},
+			expectedErr: "the state 'cold' is not defined",
+		},
+		{
+			name: "unreachable states",
+			policy: IsmPolicyModel{
+				DefaultState: "hot",
+				States: []IsmPolicyStateModel{
+					{Name: "hot"},
+					{Name: "warm", Transitions: []IsmPsTransitionModel{transition("cold")}},
+					{Name: "cold"},
+				},
+			},
+			expectedWarnings: []string{"State 'warm'", "State 'cold'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := tt.policy.ValidateGraph()
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("Expected error containing '%s', got %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+
+			if len(warnings) != len(tt.expectedWarnings) {
+				t.Fatalf("Expected %d warnings, got %v", len(tt.expectedWarnings), warnings)
+			}
+			for idx, expected := range tt.expectedWarnings {
+				if !strings.Contains(warnings[idx], expected) {
+					t.Errorf("Expected warning containing '%s', got '%s'", expected, warnings[idx])
+				}
+			}
+		})
+	}
+}
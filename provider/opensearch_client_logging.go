@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const redactedValue = "<redacted>"
+
+//Bodies that are not json are usually error pages from proxies, of which the beginning is enough
+const maxUnparsedBodyLength = 512
+
+//Keys of json fields whose values should never appear in the logs
+var sensitiveBodyKeys = []string{
+	"password",
+	"hash",
+	"secret",
+	"token",
+	"api_key",
+}
+
+var sensitiveHeaderNames = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"X-Amz-Security-Token",
+	"Cookie",
+	"Set-Cookie",
+}
+
+func isSensitiveBodyKey(key string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, sensitiveKey := range sensitiveBodyKeys {
+		if strings.Contains(lowerKey, sensitiveKey) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redactJsonValue(val interface{}) interface{} {
+	switch typedVal := val.(type) {
+	case map[string]interface{}:
+		for key, subVal := range typedVal {
+			if isSensitiveBodyKey(key) {
+				typedVal[key] = redactedValue
+			} else {
+				typedVal[key] = redactJsonValue(subVal)
+			}
+		}
+		return typedVal
+	case []interface{}:
+		for idx, subVal := range typedVal {
+			typedVal[idx] = redactJsonValue(subVal)
+		}
+		return typedVal
+	}
+
+	return val
+}
+
+//Values following a sensitive key in bodies that are not json, like "password=..." or a truncated json document
+var sensitiveUnparsedValue = regexp.MustCompile(`(?i)([\w.-]*(?:` + strings.Join(sensitiveBodyKeys, "|") + `)[\w.-]*"?\s*[:=]\s*)("[^"]*"?|[^\s,;&}\]]+)`)
+
+func redactUnparsedBody(body string) string {
+	redacted := sensitiveUnparsedValue.ReplaceAllString(body, "${1}"+redactedValue)
+	if len(redacted) > maxUnparsedBodyLength {
+		cut := maxUnparsedBodyLength
+		for cut > 0 && !utf8.RuneStart(redacted[cut]) {
+			cut -= 1
+		}
+		redacted = redacted[:cut] + "... (truncated)"
+	}
+
+	return redacted
+}
+
+//Bodies that are not json have the values of their sensitive keys redacted as well, since they can be malformed json, and are truncated
+func RedactBody(body string) string {
+	if body == "" {
+		return body
+	}
+
+	var parsed interface{}
+	uErr := json.Unmarshal([]byte(body), &parsed)
+	if uErr != nil {
+		return redactUnparsedBody(body)
+	}
+
+	redacted, marErr := json.Marshal(redactJsonValue(parsed))
+	if marErr != nil {
+		return redactedValue
+	}
+
+	return string(redacted)
+}
+
+func RedactHeaders(headers http.Header) map[string]string {
+	redacted := make(map[string]string)
+	for name, values := range headers {
+		redacted[name] = strings.Join(values, ", ")
+		for _, sensitiveName := range sensitiveHeaderNames {
+			if strings.EqualFold(name, sensitiveName) {
+				redacted[name] = redactedValue
+			}
+		}
+
+		//Custom headers can carry credentials as well
+		lowerName := strings.ToLower(name)
+		if strings.Contains(lowerName, "auth") || strings.Contains(lowerName, "token") || strings.Contains(lowerName, "key") || strings.Contains(lowerName, "secret") {
+			redacted[name] = redactedValue
+		}
+	}
+
+	return redacted
+}
+
+func (reqCon *RequestContext) getLogFields(req *http.Request) map[string]interface{} {
+	return map[string]interface{}{
+		"method":       req.Method,
+		"endpoint":     reqCon.GetCurrentEndpoint(),
+		"path":         req.URL.Path,
+		"query":        req.URL.RawQuery,
+		"attempt":      (*(*reqCon).Client).Retries - (*reqCon).RetriesLeft + 1,
+		"retries_left": (*reqCon).RetriesLeft,
+	}
+}
+
+func (reqCon *RequestContext) LogRequest(req *http.Request, body string) {
+	ctx := (*reqCon).Ctx
+	fields := reqCon.getLogFields(req)
+	tflog.Debug(ctx, "Sending opensearch request", fields)
+
+	fields["headers"] = RedactHeaders(req.Header)
+	fields["body"] = RedactBody(body)
+	tflog.Trace(ctx, "Opensearch request content", fields)
+}
+
+func (reqCon *RequestContext) LogTransportError(req *http.Request, err error, latency time.Duration) {
+	fields := reqCon.getLogFields(req)
+	fields["latency_ms"] = latency.Milliseconds()
+	fields["error"] = err.Error()
+	tflog.Warn((*reqCon).Ctx, "Opensearch request failed to complete", fields)
+}
+
+func (reqCon *RequestContext) LogResponse(req *http.Request, res *http.Response, body []byte, latency time.Duration) {
+	ctx := (*reqCon).Ctx
+	fields := reqCon.getLogFields(req)
+	fields["status"] = res.StatusCode
+	fields["latency_ms"] = latency.Milliseconds()
+	tflog.Debug(ctx, "Received opensearch response", fields)
+
+	fields["headers"] = RedactHeaders(res.Header)
+	fields["body"] = RedactBody(string(body))
+	tflog.Trace(ctx, "Opensearch response content", fields)
+}
+
+func (reqCon *RequestContext) LogRetry(delay time.Duration) {
+	tflog.Debug((*reqCon).Ctx, "Retrying opensearch request", map[string]interface{}{
+		"delay_ms":     delay.Milliseconds(),
+		"retries_left": (*reqCon).RetriesLeft,
+	})
+}
+
+//Returns the operation's context, masking the provider's secrets in the logs emitted with it
+func (cli *OpensearchClient) GetLogContext(ctx context.Context) context.Context {
+	return GetMaskedLogContext(ctx, (*cli).Secrets...)
+}
+
+//Masks the provider's secrets wherever they appear in the logs, as a safeguard on top of the redaction of known sensitive fields
+func GetMaskedLogContext(ctx context.Context, secrets ...string) context.Context {
+	nonEmptySecrets := []string{}
+	for _, secret := range secrets {
+		if secret != "" {
+			nonEmptySecrets = append(nonEmptySecrets, secret)
+		}
+	}
+
+	if len(nonEmptySecrets) == 0 {
+		return ctx
+	}
+
+	ctx = tflog.MaskAllFieldValuesStrings(ctx, nonEmptySecrets...)
+	return tflog.MaskMessageStrings(ctx, nonEmptySecrets...)
+}
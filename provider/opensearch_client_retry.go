@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Error type opensearch returns when one of its thread pools is saturated.
+//It is transient and can come with status codes that are otherwise terminal (ex: 500).
+const rejectedExecutionErrorType = "es_rejected_execution_exception"
+
+//Status codes that indicate a problem with the request itself. Re-sending the same request will not help.
+var terminalStatusCodes = []int64{400, 401, 403, 409}
+
+func GetDefaultRetryOnStatusCodes() []int64 {
+	return []int64{429, 502, 503, 504}
+}
+
+type RetryPolicy struct {
+	MinDelay           time.Duration
+	MaxDelay           time.Duration
+	RetryOnStatusCodes []int64
+}
+
+//Conditional writes only succeed if the document is still at the sequence number they specify
+func isConditionalWrite(req *http.Request) bool {
+	if req == nil || req.URL == nil {
+		return false
+	}
+
+	query := req.URL.Query()
+	return query.Has("if_seq_no") || query.Has("if_primary_term")
+}
+
+//Determines if an error response from opensearch is worth retrying.
+//A 409 on a conditional write (if_seq_no/if_primary_term) is never retried, even if it is configured to be,
+//as the same request will conflict again. It is up to the caller to refresh the sequence number and try again.
+//Other 409s, like concurrent updates of the security configuration, are only retried if configured to be.
+func (pol *RetryPolicy) IsRetriableResponse(req *http.Request, statusCode int, body string) bool {
+	if statusCode == 409 && isConditionalWrite(req) {
+		return false
+	}
+
+	if inArr(int64(statusCode), (*pol).RetryOnStatusCodes) {
+		return true
+	}
+
+	if inArr(int64(statusCode), terminalStatusCodes) {
+		return false
+	}
+
+	return strings.Contains(body, rejectedExecutionErrorType)
+}
+
+//Exponential backoff with jitter: the delay doubles on each attempt, starting at the minimum delay and capped at the maximum delay.
+//The effective wait is picked randomly between half the delay and the delay so that parallel operations do not retry in lockstep.
+//If the server specified a longer wait with a Retry-After header, it is honored, up to the maximum delay.
+func (pol *RetryPolicy) GetDelay(attempt int, res *http.Response) time.Duration {
+	delay := (*pol).MinDelay
+	for idx := 0; idx < attempt && delay < (*pol).MaxDelay; idx++ {
+		delay = delay * 2
+	}
+	if delay > (*pol).MaxDelay {
+		delay = (*pol).MaxDelay
+	}
+
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	retryAfter := getRetryAfter(res)
+	if retryAfter > delay {
+		delay = retryAfter
+		if delay > (*pol).MaxDelay {
+			delay = (*pol).MaxDelay
+		}
+	}
+
+	return delay
+}
+
+//Parses the Retry-After header which can either be a number of seconds or an http date.
+//Returns 0 if the header is absent or invalid.
+func getRetryAfter(res *http.Response) time.Duration {
+	if res == nil {
+		return 0
+	}
+
+	retryAfter := res.Header.Get("Retry-After")
+	if retryAfter == "" {
+		return 0
+	}
+
+	seconds, secondsErr := strconv.ParseInt(retryAfter, 10, 64)
+	if secondsErr == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	date, dateErr := http.ParseTime(retryAfter)
+	if dateErr == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			return 0
+		}
+		return wait
+	}
+
+	return 0
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsRetriableResponse(t *testing.T) {
+	policy := RetryPolicy{
+		MinDelay:           time.Second,
+		MaxDelay:           time.Minute,
+		RetryOnStatusCodes: GetDefaultRetryOnStatusCodes(),
+	}
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		query      string
+		retriable  bool
+	}{
+		{name: "too many requests", statusCode: 429, retriable: true},
+		{name: "bad gateway", statusCode: 502, retriable: true},
+		{name: "service unavailable", statusCode: 503, retriable: true},
+		{name: "gateway timeout", statusCode: 504, retriable: true},
+		{name: "bad request", statusCode: 400, retriable: false},
+		{name: "unauthorized", statusCode: 401, retriable: false},
+		{name: "forbidden", statusCode: 403, retriable: false},
+		{name: "conflict", statusCode: 409, retriable: false},
+		{name: "conflict on a conditional write", statusCode: 409, query: "if_seq_no=3&if_primary_term=1", retriable: false},
+		{name: "not found", statusCode: 404, retriable: false},
+		{name: "internal error", statusCode: 500, retriable: false},
+		{
+			name:       "internal error from a saturated thread pool",
+			statusCode: 500,
+			body:       `{"error":{"type":"es_rejected_execution_exception","reason":"rejected execution"},"status":500}`,
+			retriable:  true,
+		},
+		{
+			name:       "bad request is terminal even with a rejected execution",
+			statusCode: 400,
+			body:       `{"error":{"type":"es_rejected_execution_exception","reason":"rejected execution"},"status":400}`,
+			retriable:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "http://localhost:9200/_plugins/_ism/policies/demo?"+tt.query, nil)
+			if policy.IsRetriableResponse(req, tt.statusCode, tt.body) != tt.retriable {
+				t.Errorf("Expected retriable to be %t for status %d", tt.retriable, tt.statusCode)
+			}
+		})
+	}
+
+	custom := RetryPolicy{RetryOnStatusCodes: []int64{409}}
+	if !custom.IsRetriableResponse(httptest.NewRequest("PUT", "http://localhost:9200/_plugins/_security/api/roles/demo", nil), 409, "") {
+		t.Errorf("Expected the configured status codes to take precedence over the terminal ones")
+	}
+	if custom.IsRetriableResponse(httptest.NewRequest("PUT", "http://localhost:9200/_plugins/_ism/policies/demo?if_seq_no=3&if_primary_term=1", nil), 409, "") {
+		t.Errorf("Expected conflicts on conditional writes to never be retried")
+	}
+}
+
+func TestGetDelay(t *testing.T) {
+	policy := RetryPolicy{
+		MinDelay: time.Second,
+		MaxDelay: 10 * time.Second,
+	}
+
+	withRetryAfter := func(value string) *http.Response {
+		res := &http.Response{Header: http.Header{}}
+		res.Header.Set("Retry-After", value)
+		return res
+	}
+
+	tests := []struct {
+		name     string
+		attempt  int
+		res      *http.Response
+		minDelay time.Duration
+		maxDelay time.Duration
+	}{
+		{name: "first attempt", attempt: 0, minDelay: 500 * time.Millisecond, maxDelay: time.Second},
+		{name: "second attempt", attempt: 1, minDelay: time.Second, maxDelay: 2 * time.Second},
+		{name: "third attempt", attempt: 2, minDelay: 2 * time.Second, maxDelay: 4 * time.Second},
+		{name: "capped at the maximum delay", attempt: 10, minDelay: 5 * time.Second, maxDelay: 10 * time.Second},
+		{name: "retry after in seconds", attempt: 0, res: withRetryAfter("7"), minDelay: 7 * time.Second, maxDelay: 7 * time.Second},
+		{name: "retry after capped at the maximum delay", attempt: 0, res: withRetryAfter("120"), minDelay: 10 * time.Second, maxDelay: 10 * time.Second},
+		{name: "retry after shorter than the backoff", attempt: 3, res: withRetryAfter("1"), minDelay: 4 * time.Second, maxDelay: 8 * time.Second},
+		{
+			name:     "retry after as a date",
+			attempt:  0,
+			res:      withRetryAfter(time.Now().Add(8 * time.Second).UTC().Format(http.TimeFormat)),
+			minDelay: 6 * time.Second,
+			maxDelay: 8 * time.Second,
+		},
+		{
+			name:     "retry after as a past date",
+			attempt:  0,
+			res:      withRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)),
+			minDelay: 500 * time.Millisecond,
+			maxDelay: time.Second,
+		},
+		{name: "negative retry after", attempt: 0, res: withRetryAfter("-5"), minDelay: 500 * time.Millisecond, maxDelay: time.Second},
+		{name: "invalid retry after", attempt: 0, res: withRetryAfter("soon"), minDelay: 500 * time.Millisecond, maxDelay: time.Second},
+		{name: "response without retry after", attempt: 0, res: &http.Response{Header: http.Header{}}, minDelay: 500 * time.Millisecond, maxDelay: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//The jitter is random, so the bounds are checked on several draws
+			for idx := 0; idx < 50; idx++ {
+				delay := policy.GetDelay(tt.attempt, tt.res)
+				if delay < tt.minDelay || delay > tt.maxDelay {
+					t.Fatalf("Expected a delay between %s and %s, got %s", tt.minDelay, tt.maxDelay, delay)
+				}
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package provider
 import (
 	"encoding/json"
 	"io/ioutil"
-	"path"
 )
 
 type TenantPermissionModel struct {
@@ -27,6 +26,11 @@ type RoleModel struct {
 }
 
 func (reqCon *RequestContext) UpsertRole(role RoleModel) error {
+	pluginErr := (*reqCon).Client.RequirePlugin(SecurityPlugin)
+	if pluginErr != nil {
+		return pluginErr
+	}
+
 	roleStr, marErr := json.Marshal(role)
     if marErr != nil {
         return marErr
@@ -34,7 +38,7 @@ func (reqCon *RequestContext) UpsertRole(role RoleModel) error {
 
 	res, err := reqCon.Do(
 		"PUT", 
-		path.Join("_plugins/_security/api/roles/", role.Name),
+		(*reqCon).Client.GetPluginApiPath(SecurityPlugin, "api/roles", role.Name),
 		"",
 		string(roleStr),
 		[]int64{},
@@ -49,9 +53,14 @@ func (reqCon *RequestContext) UpsertRole(role RoleModel) error {
 }
 
 func (reqCon *RequestContext) GetRole(name string) (*RoleModel, error) {
+	pluginErr := (*reqCon).Client.RequirePlugin(SecurityPlugin)
+	if pluginErr != nil {
+		return nil, pluginErr
+	}
+
 	res, err := reqCon.Do(
 		"GET", 
-		path.Join("_plugins/_security/api/roles/", name),
+		(*reqCon).Client.GetPluginApiPath(SecurityPlugin, "api/roles", name),
 		"",
 		"",
 		[]int64{},
@@ -73,15 +82,24 @@ func (reqCon *RequestContext) GetRole(name string) (*RoleModel, error) {
 		return nil, uErr
 	}
 	
-	role := roleMap[name]
+	role, roleExists := roleMap[name]
+	if !roleExists {
+		return nil, &NotFoundError{Kind: "role", Name: name}
+	}
+
 	role.Name = name
 	return &role, nil
 }
 
 func (reqCon *RequestContext) DeleteRole(name string) error {
+	pluginErr := (*reqCon).Client.RequirePlugin(SecurityPlugin)
+	if pluginErr != nil {
+		return pluginErr
+	}
+
 	res, err := reqCon.Do(
 		"DELETE", 
-		path.Join("_plugins/_security/api/roles/", name),
+		(*reqCon).Client.GetPluginApiPath(SecurityPlugin, "api/roles", name),
 		"",
 		"",
 		[]int64{},
@@ -3,7 +3,6 @@ package provider
 import (
 	"encoding/json"
 	"io/ioutil"
-	"path"
 )
 
 type RoleMappingModel struct {
@@ -14,6 +13,11 @@ type RoleMappingModel struct {
 }
 
 func (reqCon *RequestContext) UpsertRoleMapping(roleMapping RoleMappingModel) error {
+	pluginErr := (*reqCon).Client.RequirePlugin(SecurityPlugin)
+	if pluginErr != nil {
+		return pluginErr
+	}
+
 	roleMappingStr, marErr := json.Marshal(roleMapping)
     if marErr != nil {
         return marErr
@@ -21,7 +25,7 @@ func (reqCon *RequestContext) UpsertRoleMapping(roleMapping RoleMappingModel) er
 
 	res, err := reqCon.Do(
 		"PUT", 
-		path.Join("_plugins/_security/api/rolesmapping/", roleMapping.Role),
+		(*reqCon).Client.GetPluginApiPath(SecurityPlugin, "api/rolesmapping", roleMapping.Role),
 		"",
 		string(roleMappingStr),
 		[]int64{},
@@ -36,9 +40,14 @@ func (reqCon *RequestContext) UpsertRoleMapping(roleMapping RoleMappingModel) er
 }
 
 func (reqCon *RequestContext) GetRoleMapping(role string) (*RoleMappingModel, error) {
+	pluginErr := (*reqCon).Client.RequirePlugin(SecurityPlugin)
+	if pluginErr != nil {
+		return nil, pluginErr
+	}
+
 	res, err := reqCon.Do(
 		"GET", 
-		path.Join("_plugins/_security/api/rolesmapping/", role),
+		(*reqCon).Client.GetPluginApiPath(SecurityPlugin, "api/rolesmapping", role),
 		"",
 		"",
 		[]int64{},
@@ -60,15 +69,24 @@ func (reqCon *RequestContext) GetRoleMapping(role string) (*RoleMappingModel, er
 		return nil, uErr
 	}
 	
-	roleMapping := roleMappingMap[role]
+	roleMapping, roleMappingExists := roleMappingMap[role]
+	if !roleMappingExists {
+		return nil, &NotFoundError{Kind: "role mapping", Name: role}
+	}
+
 	roleMapping.Role = role
 	return &roleMapping, nil
 }
 
 func (reqCon *RequestContext) DeleteRoleMapping(role string) error {
+	pluginErr := (*reqCon).Client.RequirePlugin(SecurityPlugin)
+	if pluginErr != nil {
+		return pluginErr
+	}
+
 	res, err := reqCon.Do(
 		"DELETE", 
-		path.Join("_plugins/_security/api/rolesmapping/", role),
+		(*reqCon).Client.GetPluginApiPath(SecurityPlugin, "api/rolesmapping", role),
 		"",
 		"",
 		[]int64{},
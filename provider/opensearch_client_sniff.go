@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+type NodeHttpModel struct {
+	PublishAddress string `json:"publish_address"`
+}
+
+type NodeModel struct {
+	Name  string         `json:"name"`
+	Roles []string       `json:"roles"`
+	Http  *NodeHttpModel `json:"http"`
+}
+
+//Dedicated cluster manager nodes should not be burdened with api requests
+func (node *NodeModel) IsDedicatedClusterManager() bool {
+	if len((*node).Roles) == 0 {
+		return false
+	}
+
+	for _, role := range (*node).Roles {
+		if role != "cluster_manager" && role != "master" && role != "voting_only" {
+			return false
+		}
+	}
+
+	return true
+}
+
+//Publish address is either in the ip:port or the hostname/ip:port format.
+//The hostname is preferred when present as it is more likely to match the servers' certificates.
+func (node *NodeModel) GetEndpoint(scheme string) string {
+	address := (*(*node).Http).PublishAddress
+	if strings.Contains(address, "/") {
+		parts := strings.SplitN(address, "/", 2)
+		port := parts[1][strings.LastIndex(parts[1], ":")+1:]
+		if parts[0] != "" {
+			address = fmt.Sprintf("%s:%s", parts[0], port)
+		} else {
+			address = parts[1]
+		}
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, address)
+}
+
+type NodesHttpModel struct {
+	Nodes map[string]NodeModel `json:"nodes"`
+}
+
+func (reqCon *RequestContext) GetNodesHttp() (*NodesHttpModel, error) {
+	res, err := reqCon.Do(
+		"GET",
+		"_nodes/http",
+		"",
+		"",
+		[]int64{},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	b, bErr := ioutil.ReadAll(res.Body)
+	if bErr != nil {
+		return nil, bErr
+	}
+
+	nodes := NodesHttpModel{}
+	uErr := json.Unmarshal(b, &nodes)
+	if uErr != nil {
+		return nil, uErr
+	}
+
+	return &nodes, nil
+}
+
+//Retrieves the endpoints of the cluster's nodes that can serve api requests
+func (reqCon *RequestContext) SniffEndpoints(scheme string) ([]string, error) {
+	nodes, err := reqCon.GetNodesHttp()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := []string{}
+	for _, node := range (*nodes).Nodes {
+		if node.Http == nil || node.Http.PublishAddress == "" || node.IsDedicatedClusterManager() {
+			continue
+		}
+		endpoints = append(endpoints, node.GetEndpoint(scheme))
+	}
+
+	if len(endpoints) == 0 {
+		return nil, errors.New("Sniffing did not find any node with an http publish address that is not a dedicated cluster manager")
+	}
+
+	rand.Shuffle(len(endpoints), func(i, j int) { endpoints[i], endpoints[j] = endpoints[j], endpoints[i] })
+	return endpoints, nil
+}
+
+//Refreshes run in the background, detached from the operation that triggered them, so they are bounded by their own timeout
+const sniffRefreshTimeout = 30 * time.Second
+
+//Keeps the values of the operation's context, such as its loggers, without being cancelled along with the operation
+type detachedContext struct {
+	context.Context
+}
+
+func (ctx detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (ctx detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (ctx detachedContext) Err() error {
+	return nil
+}
+
+//Keeps the sniffed endpoints up to date, sniffing again when they are older than the interval.
+//The configured endpoints are kept as seeds to sniff from in case all the sniffed nodes become unavailable.
+type EndpointsSniffer struct {
+	Interval  time.Duration
+	Scheme    string
+	Seeds     []string
+	lock      sync.Mutex
+	endpoints []string
+	lastSniff time.Time
+	sniffing  bool
+}
+
+func NewEndpointsSniffer(interval time.Duration, scheme string, seeds []string, endpoints []string) *EndpointsSniffer {
+	return &EndpointsSniffer{
+		Interval:  interval,
+		Scheme:    scheme,
+		Seeds:     seeds,
+		endpoints: endpoints,
+		lastSniff: time.Now(),
+	}
+}
+
+//Returns the current endpoints, starting a refresh in the background if they are stale.
+//Operations never wait for a refresh, they use the current endpoints until it completes.
+func (sniffer *EndpointsSniffer) GetEndpoints(ctx context.Context, cli *OpensearchClient) []string {
+	(*sniffer).lock.Lock()
+	defer (*sniffer).lock.Unlock()
+	endpoints := (*sniffer).endpoints
+	if !(*sniffer).sniffing && time.Since((*sniffer).lastSniff) >= (*sniffer).Interval {
+		(*sniffer).sniffing = true
+		go sniffer.refresh(detachedContext{ctx}, cli, endpoints)
+	}
+
+	return endpoints
+}
+
+//Sniffs from the current endpoints and falls back to the seeds if none of them answer.
+//If both fail, the current endpoints are kept.
+func (sniffer *EndpointsSniffer) refresh(ctx context.Context, cli *OpensearchClient, endpoints []string) {
+	ctx, cancel := context.WithTimeout(ctx, sniffRefreshTimeout)
+	defer cancel()
+
+	sniffed, err := cli.GetRequestContextOn(ctx, endpoints).SniffEndpoints((*sniffer).Scheme)
+	if err != nil {
+		tflog.Warn(cli.GetLogContext(ctx), fmt.Sprintf("Failed to refresh the sniffed endpoints from the sniffed nodes, falling back to the configured endpoints: %s", err.Error()))
+		sniffed, err = cli.GetRequestContextOn(ctx, (*sniffer).Seeds).SniffEndpoints((*sniffer).Scheme)
+	}
+
+	(*sniffer).lock.Lock()
+	defer (*sniffer).lock.Unlock()
+	(*sniffer).sniffing = false
+	(*sniffer).lastSniff = time.Now()
+	if err != nil {
+		tflog.Warn(cli.GetLogContext(ctx), fmt.Sprintf("Failed to refresh the sniffed endpoints, keeping the previous ones: %s", err.Error()))
+		return
+	}
+
+	(*sniffer).endpoints = sniffed
+}
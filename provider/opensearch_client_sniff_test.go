@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newNodesServer(t *testing.T, publishAddress func() string, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_nodes/http" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(fmt.Sprintf(`{"nodes":{"node-1":{"name":"node-1","roles":["data","ingest"],"http":{"publish_address":"%s"}}}}`, publishAddress())))
+	}))
+}
+
+func newSniffTestClient() *OpensearchClient {
+	return &OpensearchClient{
+		Client:      &http.Client{},
+		RetryPolicy: RetryPolicy{MinDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		Health:      NewEndpointsHealth(3, time.Minute),
+	}
+}
+
+func waitForSniffedEndpoints(t *testing.T, sniffer *EndpointsSniffer, expected string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		(*sniffer).lock.Lock()
+		endpoints := (*sniffer).endpoints
+		sniffing := (*sniffer).sniffing
+		(*sniffer).lock.Unlock()
+		if !sniffing && len(endpoints) == 1 && endpoints[0] == expected {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("The sniffed endpoints were not refreshed to '%s'", expected)
+}
+
+func TestSnifferFallsBackToSeeds(t *testing.T) {
+	var seed *httptest.Server
+	seed = newNodesServer(t, func() string {
+		u, _ := url.Parse(seed.URL)
+		return u.Host
+	}, 0)
+	defer seed.Close()
+
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	cli := newSniffTestClient()
+	sniffer := NewEndpointsSniffer(time.Millisecond, "http", []string{seed.URL}, []string{dead.URL})
+	time.Sleep(2 * time.Millisecond)
+
+	endpoints := sniffer.GetEndpoints(context.Background(), cli)
+	if len(endpoints) != 1 || endpoints[0] != dead.URL {
+		t.Errorf("Expected the current endpoints to be returned while refreshing, got %v", endpoints)
+	}
+
+	waitForSniffedEndpoints(t, sniffer, seed.URL)
+}
+
+func TestSnifferDoesNotBlockOnRefresh(t *testing.T) {
+	var slow *httptest.Server
+	slow = newNodesServer(t, func() string {
+		u, _ := url.Parse(slow.URL)
+		return u.Host
+	}, 500*time.Millisecond)
+	defer slow.Close()
+
+	cli := newSniffTestClient()
+	sniffer := NewEndpointsSniffer(time.Millisecond, "http", []string{slow.URL}, []string{"http://127.0.0.1:1"})
+	time.Sleep(2 * time.Millisecond)
+
+	//The operation's context is cancelled right away, which must not abort the refresh
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	sniffer.GetEndpoints(ctx, cli)
+	cancel()
+	if time.Since(start) > 100*time.Millisecond {
+		t.Errorf("Expected the endpoints to be returned without waiting for the refresh, took %s", time.Since(start))
+	}
+
+	waitForSniffedEndpoints(t, sniffer, slow.URL)
+}
@@ -3,7 +3,6 @@ package provider
 import (
 	"encoding/json"
 	"io/ioutil"
-	"path"
 )
 
 type UserModel struct {
@@ -14,6 +13,11 @@ type UserModel struct {
 }
 
 func (reqCon *RequestContext) UpsertUser(user UserModel) error {
+	pluginErr := (*reqCon).Client.RequirePlugin(SecurityPlugin)
+	if pluginErr != nil {
+		return pluginErr
+	}
+
 	userStr, marErr := json.Marshal(user)
     if marErr != nil {
         return marErr
@@ -21,7 +25,7 @@ func (reqCon *RequestContext) UpsertUser(user UserModel) error {
 
 	res, err := reqCon.Do(
 		"PUT", 
-		path.Join("_plugins/_security/api/internalusers/", user.Username),
+		(*reqCon).Client.GetPluginApiPath(SecurityPlugin, "api/internalusers", user.Username),
 		"",
 		string(userStr),
 		[]int64{},
@@ -36,9 +40,14 @@ func (reqCon *RequestContext) UpsertUser(user UserModel) error {
 }
 
 func (reqCon *RequestContext) GetUser(username string) (*UserModel, error) {
+	pluginErr := (*reqCon).Client.RequirePlugin(SecurityPlugin)
+	if pluginErr != nil {
+		return nil, pluginErr
+	}
+
 	res, err := reqCon.Do(
 		"GET", 
-		path.Join("_plugins/_security/api/internalusers/", username),
+		(*reqCon).Client.GetPluginApiPath(SecurityPlugin, "api/internalusers", username),
 		"",
 		"",
 		[]int64{},
@@ -60,15 +69,24 @@ func (reqCon *RequestContext) GetUser(username string) (*UserModel, error) {
 		return nil, uErr
 	}
 	
-	user := userMap[username]
+	user, userExists := userMap[username]
+	if !userExists {
+		return nil, &NotFoundError{Kind: "user", Name: username}
+	}
+
 	user.Username = username
 	return &user, nil
 }
 
 func (reqCon *RequestContext) DeleteUser(username string) error {
+	pluginErr := (*reqCon).Client.RequirePlugin(SecurityPlugin)
+	if pluginErr != nil {
+		return pluginErr
+	}
+
 	res, err := reqCon.Do(
 		"DELETE", 
-		path.Join("_plugins/_security/api/internalusers/", username),
+		(*reqCon).Client.GetPluginApiPath(SecurityPlugin, "api/internalusers", username),
 		"",
 		"",
 		[]int64{}, 
@@ -1,47 +1,249 @@
 package provider
 
 import (
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func ismStateActionRetrySchemaToModel(d map[string]interface{}) IsmPsaRetryModel {
-	model := IsmPsaRetryModel{}
+func int64PtrToFrameworkInt64(value *int64) types.Int64 {
+	if value == nil {
+		return types.Int64Null()
+	}
+
+	return types.Int64Value(*value)
+}
+
+func frameworkInt64ToInt64Ptr(value types.Int64) *int64 {
+	if value.IsNull() || value.IsUnknown() {
+		return nil
+	}
 
-	count := d["count"]
-	model.Count = int64(count.(int))
+	valueInt64 := value.ValueInt64()
+	return &valueInt64
+}
 
-	backoff, backoffExists := d["backoff"]
-	if backoffExists {
-		model.Backoff = backoff.(string)
+func ismRetryResourceModelToModel(m *ismRetryResourceModel) *IsmPsaRetryModel {
+	if m == nil {
+		return nil
 	}
 
-	delay, delayExists := d["delay"]
-	if delayExists {
-		model.Delay = delay.(string)
+	return &IsmPsaRetryModel{
+		Count:   m.Count.ValueInt64(),
+		Backoff: m.Backoff.ValueString(),
+		Delay:   m.Delay.ValueString(),
+	}
+}
+
+//Rollovers without conditions are unconditional
+func ismRolloverResourceModelToModel(m *ismRolloverResourceModel) *IsmPsaRolloverModel {
+	if m == nil {
+		return &IsmPsaRolloverModel{}
+	}
+
+	return &IsmPsaRolloverModel{
+		MinSize:             m.MinSize.ValueString(),
+		MinPrimaryShardSize: m.MinPrimaryShardSize.ValueString(),
+		MinDocCount:         frameworkInt64ToInt64Ptr(m.MinDocCount),
+		MinIndexAge:         m.MinIndexAge.ValueString(),
+		CopyAlias:           m.CopyAlias.ValueBool(),
+	}
+}
+
+func ismShrinkResourceModelToModel(m *ismShrinkResourceModel) *IsmPsaShrinkModel {
+	model := IsmPsaShrinkModel{}
+	if m == nil {
+		return &model
+	}
+
+	model.NumNewShards = m.NumNewShards.ValueInt64()
+	model.MaxShardSize = m.MaxShardSize.ValueString()
+	model.PercentageOfSourceShards = m.PercentageOfSourceShards.ValueFloat64()
+	model.ForceUnsafe = m.ForceUnsafe.ValueBool()
+	if !m.TargetIndexNameSuffix.IsNull() {
+		model.TargetIndexNameTemplate = &IsmScriptModel{
+			Source: shrinkTargetIndexNamePrefix + m.TargetIndexNameSuffix.ValueString(),
+		}
+	}
+
+	return &model
+}
+
+func ismAllocationResourceModelToModel(ctx context.Context, m *ismAllocationResourceModel) (*IsmPsaAllocationModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var mapDiags diag.Diagnostics
+
+	model := IsmPsaAllocationModel{}
+	if m == nil {
+		return &model, diags
+	}
+
+	model.Require, mapDiags = frameworkMapToStrings(ctx, m.Require)
+	diags.Append(mapDiags...)
+	model.Include, mapDiags = frameworkMapToStrings(ctx, m.Include)
+	diags.Append(mapDiags...)
+	model.Exclude, mapDiags = frameworkMapToStrings(ctx, m.Exclude)
+	diags.Append(mapDiags...)
+	model.WaitFor = m.WaitFor.ValueBool()
+
+	return &model, diags
+}
+
+func ismSnapshotResourceModelToModel(m *ismSnapshotResourceModel) *IsmPsaSnapshotModel {
+	if m == nil {
+		return &IsmPsaSnapshotModel{}
+	}
+
+	return &IsmPsaSnapshotModel{
+		Repository: m.Repository.ValueString(),
+		Snapshot:   m.Snapshot.ValueString(),
+	}
+}
+
+func ismDimensionResourceModelToModel(m ismDimensionResourceModel) IsmDimensionModel {
+	model := IsmDimensionModel{}
+
+	sourceField := m.SourceField.ValueString()
+	targetField := m.TargetField.ValueString()
+	switch m.Type.ValueString() {
+	case "date_histogram":
+		model.DateHistogram = &IsmDateHistogramModel{
+			SourceField:      sourceField,
+			TargetField:      targetField,
+			FixedInterval:    m.FixedInterval.ValueString(),
+			CalendarInterval: m.CalendarInterval.ValueString(),
+			Timezone:         m.Timezone.ValueString(),
+		}
+	case "terms":
+		model.Terms = &IsmTermsModel{
+			SourceField: sourceField,
+			TargetField: targetField,
+		}
+	case "histogram":
+		model.Histogram = &IsmHistogramModel{
+			SourceField: sourceField,
+			TargetField: targetField,
+			Interval:    m.Interval.ValueFloat64(),
+		}
 	}
 
 	return model
 }
 
-func ismStateActionSchemaToModel(d map[string]interface{}) IsmPsActionModel {
-	model := IsmPsActionModel{}
+func ismDimensionsResourceModelToModel(m []ismDimensionResourceModel) []IsmDimensionModel {
+	model := []IsmDimensionModel{}
+	for _, val := range m {
+		model = append(model, ismDimensionResourceModelToModel(val))
+	}
+	return model
+}
+
+func ismRollupResourceModelToModel(ctx context.Context, m *ismRollupResourceModel) (*IsmPsaRollupModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	model := IsmRollupModel{
+		Dimensions: []IsmDimensionModel{},
+		Metrics:    []IsmRollupMetricModel{},
+	}
+	if m == nil {
+		return &IsmPsaRollupModel{IsmRollup: model}, diags
+	}
+
+	model.Description = m.Description.ValueString()
+	model.TargetIndex = m.TargetIndex.ValueString()
+	model.PageSize = m.PageSize.ValueInt64()
+	model.Dimensions = ismDimensionsResourceModelToModel(m.Dimensions)
+
+	for _, val := range m.Metrics {
+		metricNames, setDiags := frameworkSetToStrings(ctx, val.Metrics)
+		diags.Append(setDiags...)
+
+		metric := IsmRollupMetricModel{
+			SourceField: val.SourceField.ValueString(),
+			Metrics:     []map[string]EmptyModel{},
+		}
+		for _, metricName := range metricNames {
+			metric.Metrics = append(metric.Metrics, map[string]EmptyModel{metricName: EmptyModel{}})
+		}
+		model.Metrics = append(model.Metrics, metric)
+	}
+
+	return &IsmPsaRollupModel{IsmRollup: model}, diags
+}
+
+func ismTransformResourceModelToModel(m *ismTransformResourceModel) *IsmPsaTransformModel {
+	model := IsmTransformModel{
+		Groups: []IsmDimensionModel{},
+	}
+	if m == nil {
+		return &IsmPsaTransformModel{IsmTransform: model}
+	}
+
+	model.Description = m.Description.ValueString()
+	model.TargetIndex = m.TargetIndex.ValueString()
+	model.PageSize = m.PageSize.ValueInt64()
+	model.Groups = ismDimensionsResourceModelToModel(m.Groups)
+	if m.DataSelectionQuery.ValueString() != "" {
+		model.DataSelectionQuery = json.RawMessage(m.DataSelectionQuery.ValueString())
+	}
+	if m.Aggregations.ValueString() != "" {
+		model.Aggregations = json.RawMessage(m.Aggregations.ValueString())
+	}
+
+	return &IsmPsaTransformModel{IsmTransform: model}
+}
+
+func ismNotificationResourceModelToModel(m *ismNotificationResourceModel) *IsmNotificationModel {
+	model := IsmNotificationModel{}
+	if m == nil {
+		return &model
+	}
 
-	timeout, timeoutExists := d["timeout"]
-	if timeoutExists {
-		model.Timeout = timeout.(string)
+	if m.ChannelId.ValueString() != "" {
+		model.Channel = &IsmChannelModel{
+			Id: m.ChannelId.ValueString(),
+		}
 	}
 
-	retry, retryExists := d["retry"]
-	if retryExists {
-		for _, val := range (retry.(*schema.Set)).List() {
-			retry := ismStateActionRetrySchemaToModel(val.(map[string]interface{}))
-			model.Retry = &retry
+	if m.Destination != nil {
+		webhook := IsmWebhookModel{
+			Url: m.Destination.Url.ValueString(),
+		}
+
+		model.Destination = &IsmDestinationModel{}
+		switch m.Destination.Type.ValueString() {
+		case "slack":
+			model.Destination.Slack = &webhook
+		case "chime":
+			model.Destination.Chime = &webhook
+		case "custom_webhook":
+			model.Destination.CustomWebhook = &webhook
 		}
 	}
 
-	action := d["action"]
-	actionStr := action.(string)
-	switch actionStr {
+	if m.MessageTemplate != nil {
+		model.MessageTemplate = IsmScriptModel{
+			Source: m.MessageTemplate.Source.ValueString(),
+			Lang:   m.MessageTemplate.Lang.ValueString(),
+		}
+	}
+
+	return &model
+}
+
+func ismActionResourceModelToModel(ctx context.Context, m ismActionResourceModel) (IsmPsActionModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	model := IsmPsActionModel{
+		Timeout: m.Timeout.ValueString(),
+		Retry:   ismRetryResourceModelToModel(m.Retry),
+	}
+
+	switch m.Action.ValueString() {
 	case "read_only":
 		model.ReadOnly = &EmptyModel{}
 	case "read_write":
@@ -53,250 +255,623 @@ func ismStateActionSchemaToModel(d map[string]interface{}) IsmPsActionModel {
 	case "delete":
 		model.Delete = &EmptyModel{}
 	case "replica_count":
-		replicaCountInt64 := int64(-1)
-		replicaCount, replicaCountExists := d["replica_count"]
-		if replicaCountExists {
-			replicaCountInt64 = int64(replicaCount.(int))
-		}
 		model.ReplicaCount = &IsmPsaReplicaCountModel{
-			ReplicaCount: replicaCountInt64,
+			ReplicaCount: m.ReplicaCount.ValueInt64(),
 		}
 	case "index_priority":
-		indexPriorityint64 := int64(-1)
-		indexPriority, indexPriorityExists := d["index_priority"]
-		if indexPriorityExists {
-			indexPriorityint64 = int64(indexPriority.(int))
-		}
 		model.IndexPriority = &IsmPsaIndexPriorityModel{
-			IndexPriority: indexPriorityint64,
+			IndexPriority: m.IndexPriority.ValueInt64(),
+		}
+	case "rollover":
+		model.Rollover = ismRolloverResourceModelToModel(m.Rollover)
+	case "force_merge":
+		model.ForceMerge = &IsmPsaForceMergeModel{
+			MaxNumSegments: -1,
+		}
+		if m.ForceMerge != nil {
+			model.ForceMerge.MaxNumSegments = m.ForceMerge.MaxNumSegments.ValueInt64()
 		}
+	case "shrink":
+		model.Shrink = ismShrinkResourceModelToModel(m.Shrink)
+	case "allocation":
+		allocation, allocationDiags := ismAllocationResourceModelToModel(ctx, m.Allocation)
+		diags.Append(allocationDiags...)
+		model.Allocation = allocation
+	case "snapshot":
+		model.Snapshot = ismSnapshotResourceModelToModel(m.Snapshot)
+	case "rollup":
+		rollup, rollupDiags := ismRollupResourceModelToModel(ctx, m.Rollup)
+		diags.Append(rollupDiags...)
+		model.Rollup = rollup
+	case "notification":
+		model.Notification = ismNotificationResourceModelToModel(m.Notification)
+	case "transform":
+		model.Transform = ismTransformResourceModelToModel(m.Transform)
 	}
 
-	return model
+	return model, diags
 }
 
-func ismPstConditionSchemaToModel(d map[string]interface{}) IsmPstConditionModel {
-	model := IsmPstConditionModel{}
-
-	minIndexAge, minIndexAgeExists := d["min_index_age"]
-	if minIndexAgeExists {
-		model.MinIndexAge = minIndexAge.(string)
+func ismConditionsResourceModelToModel(m *ismConditionsResourceModel) *IsmPstConditionModel {
+	if m == nil {
+		return nil
 	}
-	
-	minDocCount, minDocCountExists := d["min_doc_count"]
-	if minDocCountExists {
-		model.MinDocCount = int64(minDocCount.(int))
+
+	model := IsmPstConditionModel{
+		MinIndexAge:    m.MinIndexAge.ValueString(),
+		MinRolloverAge: m.MinRolloverAge.ValueString(),
+		MinDocCount:    frameworkInt64ToInt64Ptr(m.MinDocCount),
+		MinSize:        m.MinSize.ValueString(),
 	}
 
-	minSize, minSizeExists := d["min_size"]
-	if minSizeExists {
-		model.MinSize = minSize.(string)
+	if m.Cron != nil {
+		model.Cron = &IsmCronModel{
+			Cron: IsmCronExpressionModel{
+				Expression: m.Cron.Expression.ValueString(),
+				Timezone:   m.Cron.Timezone.ValueString(),
+			},
+		}
 	}
 
-	return model
+	return &model
 }
 
-func ismStateTransitionSchemaToModel(d map[string]interface{}) IsmPsTransitionModel {
-	model := IsmPsTransitionModel{}
+func ismStateResourceModelToModel(ctx context.Context, m ismStateResourceModel) (IsmPolicyStateModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
 
-	stateName := d["state_name"]
-	model.StateName = stateName.(string)
+	model := IsmPolicyStateModel{
+		Name:        m.Name.ValueString(),
+		Actions:     []IsmPsActionModel{},
+		Transitions: []IsmPsTransitionModel{},
+	}
 
-	conditions := d["conditions"]
-	for _, val := range (conditions.(*schema.Set)).List() {
-		model.Conditions = ismPstConditionSchemaToModel(val.(map[string]interface{}))
+	for _, val := range m.Actions {
+		action, actionDiags := ismActionResourceModelToModel(ctx, val)
+		diags.Append(actionDiags...)
+		model.Actions = append(model.Actions, action)
 	}
 
-	return model
+	for _, val := range m.Transitions {
+		model.Transitions = append(model.Transitions, IsmPsTransitionModel{
+			StateName:  val.StateName.ValueString(),
+			Conditions: ismConditionsResourceModelToModel(val.Conditions),
+		})
+	}
+
+	return model, diags
 }
 
-func ismStateSchemaToModel(d map[string]interface{}) IsmPolicyStateModel {
-	model := IsmPolicyStateModel{
-		Actions:     []IsmPsActionModel{},
-		Transitions: []IsmPsTransitionModel{},
+func ismChangePolicyResourceModelToModel(ctx context.Context, policyId string, m ismUpdateManagedIndicesResourceModel) (IsmChangePolicyModel, diag.Diagnostics) {
+	model := IsmChangePolicyModel{
+		PolicyId: policyId,
+		State:    m.State.ValueString(),
+		Include:  []IsmStateFilterModel{},
 	}
 
-	name, _ := d["name"]
-	model.Name = name.(string)
+	include, diags := frameworkSetToStrings(ctx, m.Include)
+	for _, val := range include {
+		model.Include = append(model.Include, IsmStateFilterModel{
+			State: val,
+		})
+	}
 
-	actions, actionsExist := d["actions"]
-	if actionsExist {
-		for _, val := range actions.([]interface{}) {
-			model.Actions = append(model.Actions, ismStateActionSchemaToModel(val.(map[string]interface{})))
-		}
+	return model, diags
+}
+
+func ismPolicyResourceModelToModel(ctx context.Context, m ismPolicyResourceModel) (IsmPolicyModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	model := IsmPolicyModel{
+		PolicyId:     m.PolicyId.ValueString(),
+		Description:  m.Description.ValueString(),
+		DefaultState: m.DefaultState.ValueString(),
+		States:       []IsmPolicyStateModel{},
+		IsmTemplate:  []IsmTemplateModel{},
 	}
 
-	transitions, transitionsExist := d["transitions"]
-	if transitionsExist {
-		for _, val := range transitions.([]interface{}) {
-			model.Transitions = append(model.Transitions, ismStateTransitionSchemaToModel(val.(map[string]interface{})))
-		}
+	for _, val := range m.IsmTemplate {
+		indexPatterns, setDiags := frameworkSetToStrings(ctx, val.IndexPatterns)
+		diags.Append(setDiags...)
+		model.IsmTemplate = append(model.IsmTemplate, IsmTemplateModel{
+			Priority:      frameworkInt64ToInt64Ptr(val.Priority),
+			IndexPatterns: indexPatterns,
+		})
 	}
 
-	return model
+	if m.ErrorNotification != nil {
+		model.ErrorNotification = ismNotificationResourceModelToModel(m.ErrorNotification)
+	}
+
+	for _, val := range m.States {
+		state, stateDiags := ismStateResourceModelToModel(ctx, val)
+		diags.Append(stateDiags...)
+		model.States = append(model.States, state)
+	}
+
+	return model, diags
 }
 
-func ismTemplateSchemaToModel(d map[string]interface{}) IsmTemplateModel {
-	model := IsmTemplateModel{
-		IndexPatterns: []string{},
+//Values returned by the api that match the ones of the prior state are written as they were in it,
+//so that arguments left unset in the configuration and set to their default value by opensearch do not produce diffs
+func ismRetryModelToResourceModel(m *IsmPsaRetryModel, prior *ismRetryResourceModel) *ismRetryResourceModel {
+	if m == nil {
+		return nil
 	}
 
-	priority, priorityExists := d["priority"]
-	if priorityExists {
-		priorityInt64 := int64(priority.(int))
-		model.Priority = &priorityInt64
+	if prior == nil {
+		prior = &ismRetryResourceModel{}
 	}
 
-	indexPatterns, _ := d["index_patterns"]
-	for _, val := range (indexPatterns.(*schema.Set)).List() {
-		model.IndexPatterns = append(model.IndexPatterns, val.(string))
+	model := ismRetryResourceModel{
+		Count:   types.Int64Value(m.Count),
+		Backoff: stringToFrameworkString(m.Backoff, prior.Backoff),
+		Delay:   stringToFrameworkString(m.Delay, prior.Delay),
 	}
-	
-	return model
+	if m.Backoff == "exponential" && prior.Backoff.IsNull() {
+		model.Backoff = types.StringNull()
+	}
+	if m.Delay == "1m" && prior.Delay.IsNull() {
+		model.Delay = types.StringNull()
+	}
+
+	return &model
 }
 
-func ismPolicySchemaToModel(d *schema.ResourceData) IsmPolicyModel {
-	model := IsmPolicyModel{
-		States:      []IsmPolicyStateModel{},
-		IsmTemplate: []IsmTemplateModel{},
+func ismNotificationModelToResourceModel(m *IsmNotificationModel) *ismNotificationResourceModel {
+	model := ismNotificationResourceModel{
+		ChannelId: types.StringNull(),
+		MessageTemplate: &ismMessageTemplateResourceModel{
+			Source: types.StringValue(m.MessageTemplate.Source),
+			Lang:   types.StringValue(m.MessageTemplate.Lang),
+		},
 	}
 
-	policyId, _ := d.GetOk("policy_id")
-	model.PolicyId = policyId.(string)
-
-	description, _ := d.GetOk("description")
-	model.Description = description.(string)
+	if m.Channel != nil {
+		model.ChannelId = types.StringValue(m.Channel.Id)
+	}
 
-	ismTemplate, ismTemplateExist := d.GetOk("ism_template")
-	if ismTemplateExist {
-		for _, val := range (ismTemplate.(*schema.Set)).List() {
-			ismTemplateModel := ismTemplateSchemaToModel(val.(map[string]interface{}))
-			model.IsmTemplate = append(model.IsmTemplate, ismTemplateModel)
+	if m.Destination != nil {
+		if m.Destination.Slack != nil {
+			model.Destination = &ismDestinationResourceModel{Type: types.StringValue("slack"), Url: types.StringValue(m.Destination.Slack.Url)}
+		} else if m.Destination.Chime != nil {
+			model.Destination = &ismDestinationResourceModel{Type: types.StringValue("chime"), Url: types.StringValue(m.Destination.Chime.Url)}
+		} else if m.Destination.CustomWebhook != nil {
+			model.Destination = &ismDestinationResourceModel{Type: types.StringValue("custom_webhook"), Url: types.StringValue(m.Destination.CustomWebhook.Url)}
 		}
 	}
 
-	defaultState, _ := d.GetOk("default_state")
-	model.DefaultState = defaultState.(string)
+	if m.MessageTemplate.Lang == "" {
+		model.MessageTemplate.Lang = types.StringValue("mustache")
+	}
 
-	states, _ := d.GetOk("states")
-	for _, val := range (states.(*schema.Set)).List() {
-		model.States = append(model.States, ismStateSchemaToModel(val.(map[string]interface{})))
+	return &model
+}
+
+//The api fills in the target field and the timezone when they are omitted
+func ismDimensionsModelToResourceModel(m []IsmDimensionModel, prior []ismDimensionResourceModel) []ismDimensionResourceModel {
+	dimensions := []ismDimensionResourceModel{}
+	for idx, dim := range m {
+		priorDim := ismDimensionResourceModel{}
+		if len(prior) == len(m) {
+			priorDim = prior[idx]
+		}
+
+		dimension := ismDimensionResourceModel{}
+		sourceField := ""
+		targetField := ""
+		if dim.DateHistogram != nil {
+			dimension.Type = types.StringValue("date_histogram")
+			sourceField = dim.DateHistogram.SourceField
+			targetField = dim.DateHistogram.TargetField
+			dimension.FixedInterval = stringToFrameworkString(dim.DateHistogram.FixedInterval, types.StringNull())
+			dimension.CalendarInterval = stringToFrameworkString(dim.DateHistogram.CalendarInterval, types.StringNull())
+			dimension.Timezone = stringToFrameworkString(dim.DateHistogram.Timezone, priorDim.Timezone)
+			if dim.DateHistogram.Timezone == "UTC" && priorDim.Timezone.IsNull() {
+				dimension.Timezone = types.StringNull()
+			}
+		} else if dim.Terms != nil {
+			dimension.Type = types.StringValue("terms")
+			sourceField = dim.Terms.SourceField
+			targetField = dim.Terms.TargetField
+		} else if dim.Histogram != nil {
+			dimension.Type = types.StringValue("histogram")
+			sourceField = dim.Histogram.SourceField
+			targetField = dim.Histogram.TargetField
+			dimension.Interval = types.Float64Value(dim.Histogram.Interval)
+		}
+
+		dimension.SourceField = types.StringValue(sourceField)
+		dimension.TargetField = stringToFrameworkString(targetField, priorDim.TargetField)
+		if targetField == sourceField && priorDim.TargetField.IsNull() {
+			dimension.TargetField = types.StringNull()
+		}
+
+		dimensions = append(dimensions, dimension)
 	}
 
-	return model
+	return dimensions
 }
 
-func writeIsmPolicyModelToSchema(d *schema.ResourceData, m *IsmPolicyModel) {
-	previousPolicy := ismPolicySchemaToModel(d)
+func ismActionModelToResourceModel(ctx context.Context, a IsmPsActionModel, prior ismActionResourceModel) (ismActionResourceModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
 
-	d.Set("description", m.Description)
-	d.Set("default_state", m.DefaultState)
+	action := ismActionResourceModel{
+		Timeout: stringToFrameworkString(a.Timeout, types.StringNull()),
+		Retry:   ismRetryModelToResourceModel(a.Retry, prior.Retry),
+	}
 
-	if len(m.IsmTemplate) > 0 {
-		ismTemplate := make([]map[string]interface{}, 0)
-		
-		for _, val := range m.IsmTemplate {
-			ismTemplateElem := map[string]interface{}{
-				"index_patterns": val.IndexPatterns,
+	if a.ReadOnly != nil {
+		action.Action = types.StringValue("read_only")
+	} else if a.ReadWrite != nil {
+		action.Action = types.StringValue("read_write")
+	} else if a.Open != nil {
+		action.Action = types.StringValue("open")
+	} else if a.Close != nil {
+		action.Action = types.StringValue("close")
+	} else if a.Delete != nil {
+		action.Action = types.StringValue("delete")
+	} else if a.ReplicaCount != nil {
+		action.Action = types.StringValue("replica_count")
+		action.ReplicaCount = types.Int64Value(a.ReplicaCount.ReplicaCount)
+	} else if a.IndexPriority != nil {
+		action.Action = types.StringValue("index_priority")
+		action.IndexPriority = types.Int64Value(a.IndexPriority.IndexPriority)
+	} else if a.Rollover != nil {
+		action.Action = types.StringValue("rollover")
+		if !a.Rollover.IsEmpty() || prior.Rollover != nil {
+			action.Rollover = &ismRolloverResourceModel{
+				MinSize:             stringToFrameworkString(a.Rollover.MinSize, types.StringNull()),
+				MinPrimaryShardSize: stringToFrameworkString(a.Rollover.MinPrimaryShardSize, types.StringNull()),
+				MinDocCount:         int64PtrToFrameworkInt64(a.Rollover.MinDocCount),
+				MinIndexAge:         stringToFrameworkString(a.Rollover.MinIndexAge, types.StringNull()),
+				CopyAlias:           types.BoolValue(a.Rollover.CopyAlias),
 			}
-			
-			if val.Priority != nil {
-				ismTemplateElem["priority"] = (*val.Priority)
+		}
+	} else if a.ForceMerge != nil {
+		action.Action = types.StringValue("force_merge")
+		action.ForceMerge = &ismForceMergeResourceModel{
+			MaxNumSegments: types.Int64Value(a.ForceMerge.MaxNumSegments),
+		}
+	} else if a.Shrink != nil {
+		action.Action = types.StringValue("shrink")
+		action.Shrink = &ismShrinkResourceModel{
+			NumNewShards:             int64ToFrameworkInt64(a.Shrink.NumNewShards, types.Int64Null()),
+			MaxShardSize:             stringToFrameworkString(a.Shrink.MaxShardSize, types.StringNull()),
+			PercentageOfSourceShards: float64ToFrameworkFloat64(a.Shrink.PercentageOfSourceShards, types.Float64Null()),
+			TargetIndexNameSuffix:    types.StringNull(),
+			ForceUnsafe:              types.BoolValue(a.Shrink.ForceUnsafe),
+		}
+		if a.Shrink.TargetIndexNameTemplate != nil {
+			action.Shrink.TargetIndexNameSuffix = types.StringValue(strings.TrimPrefix(a.Shrink.TargetIndexNameTemplate.Source, shrinkTargetIndexNamePrefix))
+		}
+	} else if a.Allocation != nil {
+		action.Action = types.StringValue("allocation")
+		priorAllocation := prior.Allocation
+		if priorAllocation == nil {
+			priorAllocation = &ismAllocationResourceModel{}
+		}
+
+		var mapDiags diag.Diagnostics
+		allocation := ismAllocationResourceModel{
+			WaitFor: types.BoolValue(a.Allocation.WaitFor),
+		}
+		allocation.Require, mapDiags = stringsToFrameworkMap(ctx, a.Allocation.Require, priorAllocation.Require)
+		diags.Append(mapDiags...)
+		allocation.Include, mapDiags = stringsToFrameworkMap(ctx, a.Allocation.Include, priorAllocation.Include)
+		diags.Append(mapDiags...)
+		allocation.Exclude, mapDiags = stringsToFrameworkMap(ctx, a.Allocation.Exclude, priorAllocation.Exclude)
+		diags.Append(mapDiags...)
+		action.Allocation = &allocation
+	} else if a.Snapshot != nil {
+		action.Action = types.StringValue("snapshot")
+		action.Snapshot = &ismSnapshotResourceModel{
+			Repository: types.StringValue(a.Snapshot.Repository),
+			Snapshot:   types.StringValue(a.Snapshot.Snapshot),
+		}
+	} else if a.Rollup != nil {
+		action.Action = types.StringValue("rollup")
+		r := a.Rollup.IsmRollup
+		priorRollup := prior.Rollup
+		if priorRollup == nil {
+			priorRollup = &ismRollupResourceModel{}
+		}
+
+		metrics := []ismRollupMetricResourceModel{}
+		for _, metric := range r.Metrics {
+			metricNames := []string{}
+			for _, metricMap := range metric.Metrics {
+				for metricName, _ := range metricMap {
+					metricNames = append(metricNames, metricName)
+				}
 			}
-			
-			ismTemplate = append(ismTemplate, ismTemplateElem)
+
+			metricSet, setDiags := stringsToFrameworkSet(ctx, metricNames, types.SetNull(types.StringType))
+			diags.Append(setDiags...)
+			metrics = append(metrics, ismRollupMetricResourceModel{
+				SourceField: types.StringValue(metric.SourceField),
+				Metrics:     metricSet,
+			})
+		}
+
+		action.Rollup = &ismRollupResourceModel{
+			Description: types.StringValue(r.Description),
+			TargetIndex: types.StringValue(r.TargetIndex),
+			PageSize:    types.Int64Value(r.PageSize),
+			Dimensions:  ismDimensionsModelToResourceModel(r.Dimensions, priorRollup.Dimensions),
+			Metrics:     metrics,
 		}
+	} else if a.Notification != nil {
+		action.Action = types.StringValue("notification")
+		action.Notification = ismNotificationModelToResourceModel(a.Notification)
+	} else if a.Transform != nil {
+		action.Action = types.StringValue("transform")
+		t := a.Transform.IsmTransform
+		priorTransform := prior.Transform
+		if priorTransform == nil {
+			priorTransform = &ismTransformResourceModel{}
+		}
+
+		action.Transform = &ismTransformResourceModel{
+			Description:        types.StringValue(t.Description),
+			TargetIndex:        types.StringValue(t.TargetIndex),
+			PageSize:           types.Int64Value(t.PageSize),
+			DataSelectionQuery: stringToFrameworkString(string(t.DataSelectionQuery), types.StringNull()),
+			Groups:             ismDimensionsModelToResourceModel(t.Groups, priorTransform.Groups),
+			Aggregations:       stringToFrameworkString(string(t.Aggregations), types.StringNull()),
+		}
+	}
 
-		d.Set("ism_template", ismTemplate)
+	return action, diags
+}
+
+func ismTransitionModelToResourceModel(t IsmPsTransitionModel) ismTransitionResourceModel {
+	transition := ismTransitionResourceModel{
+		StateName: types.StringValue(t.StateName),
+	}
+
+	if t.Conditions != nil {
+		c := t.Conditions
+		transition.Conditions = &ismConditionsResourceModel{
+			MinIndexAge:    stringToFrameworkString(c.MinIndexAge, types.StringNull()),
+			MinRolloverAge: stringToFrameworkString(c.MinRolloverAge, types.StringNull()),
+			MinDocCount:    int64PtrToFrameworkInt64(c.MinDocCount),
+			MinSize:        stringToFrameworkString(c.MinSize, types.StringNull()),
+		}
+
+		if c.Cron != nil {
+			transition.Conditions.Cron = &ismCronResourceModel{
+				Expression: types.StringValue(c.Cron.Cron.Expression),
+				Timezone:   types.StringValue(c.Cron.Cron.Timezone),
+			}
+		}
+	}
+
+	return transition
+}
+
+func ismTemplatesModelToResourceModel(ctx context.Context, templates []IsmTemplateModel) ([]ismTemplateResourceModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	elems := []ismTemplateResourceModel{}
+	for _, template := range templates {
+		indexPatterns, setDiags := stringsToFrameworkSet(ctx, template.IndexPatterns, types.SetNull(types.StringType))
+		diags.Append(setDiags...)
+		elem := ismTemplateResourceModel{
+			Priority:      types.Int64Value(0),
+			IndexPatterns: indexPatterns,
+		}
+		if template.Priority != nil {
+			elem.Priority = types.Int64Value(*template.Priority)
+		}
+
+		elems = append(elems, elem)
+	}
+
+	return elems, diags
+}
+
+func writeIsmPolicyModelToResourceModel(ctx context.Context, m *IsmPolicyModel, state *ismPolicyResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	previousPolicy, previousDiags := ismPolicyResourceModelToModel(ctx, *state)
+	diags.Append(previousDiags...)
+
+	state.Description = stringToFrameworkString(m.Description, state.Description)
+	state.DefaultState = stringToFrameworkString(m.DefaultState, state.DefaultState)
+
+	if m.ErrorNotification != nil {
+		state.ErrorNotification = ismNotificationModelToResourceModel(m.ErrorNotification)
 	} else {
-		d.Set("ism_template", nil)
+		state.ErrorNotification = nil
+	}
+
+	ismTemplate, templateDiags := ismTemplatesModelToResourceModel(ctx, m.IsmTemplate)
+	diags.Append(templateDiags...)
+
+	priorStates := map[string]ismStateResourceModel{}
+	for _, priorState := range state.States {
+		priorStates[priorState.Name.ValueString()] = priorState
 	}
 
-	states := make([]map[string]interface{}, 0)
+	states := []ismStateResourceModel{}
 	for _, v := range m.States {
 		prevState := previousPolicy.GetStateNamed(v.Name)
+		priorState := priorStates[v.Name]
+
+		adjustedActions := prevState.GetDefaultRetriesAdjustedActions(&v)
+		adjustedActions = prevState.GetTransformJsonAdjustedActions(adjustedActions)
 
-		stateElem := map[string]interface{}{
-			"name": v.Name,
+		stateElem := ismStateResourceModel{
+			Name:        types.StringValue(v.Name),
+			Actions:     []ismActionResourceModel{},
+			Transitions: []ismTransitionResourceModel{},
 		}
 
-		adjustedActions := prevState.GetDefaultRetriesAdjustedActions(&v)
-		if len(adjustedActions) > 0 {
+		for idx, a := range adjustedActions {
+			priorAction := ismActionResourceModel{}
+			if len(priorState.Actions) == len(adjustedActions) {
+				priorAction = priorState.Actions[idx]
+			}
 
-			actions := make([]map[string]interface{}, 0)
-			for _, a := range adjustedActions {
-				actionElem := map[string]interface{}{}
-				if a.Timeout != "" {
-					actionElem["timeout"] = a.Timeout
-				}
-	
-				if a.Retry != nil {
-					retryElem := map[string]interface{}{}
-	
-					retryElem["count"] = a.Retry.Count
-					if a.Retry.Backoff != "" {
-						retryElem["backoff"] = a.Retry.Backoff
-					}
-					if a.Retry.Delay != "" {
-						retryElem["delay"] = a.Retry.Delay
-					}
-	
-					actionElem["retry"] = []map[string]interface{}{retryElem}
-				}
-	
-				if a.ReadOnly != nil {
-					actionElem["action"] = "read_only"
-				} else if a.ReadWrite != nil {
-					actionElem["action"] = "read_write"
-				} else if a.Open != nil {
-					actionElem["action"] = "open"
-				} else if a.Close != nil {
-					actionElem["action"] = "close"
-				} else if a.Delete != nil {
-					actionElem["action"] = "delete"
-				} else if a.ReplicaCount != nil {
-					actionElem["action"] = "replica_count"
-					actionElem["replica_count"] = a.ReplicaCount.ReplicaCount
-				} else if a.IndexPriority != nil {
-					actionElem["action"] = "index_priority"
-					actionElem["index_priority"] = a.IndexPriority.IndexPriority
-				}
-	
-				actions = append(actions, actionElem)
+			action, actionDiags := ismActionModelToResourceModel(ctx, a, priorAction)
+			diags.Append(actionDiags...)
+			stateElem.Actions = append(stateElem.Actions, action)
+		}
+
+		for _, t := range v.Transitions {
+			stateElem.Transitions = append(stateElem.Transitions, ismTransitionModelToResourceModel(t))
+		}
+
+		states = append(states, stateElem)
+	}
+
+	if diags.HasError() {
+		return diags
+	}
+
+	state.IsmTemplate = ismTemplate
+	state.States = states
+	return diags
+}
+
+//Fields opensearch adds to the policies it returns, which are not part of the policy itself
+var ismPolicyServerFields = []string{
+	"policy_id",
+	"last_updated_time",
+	"schema_version",
+	"user",
+}
+
+func isDefaultIsmRetry(retry interface{}) bool {
+	retryMap, isMap := retry.(map[string]interface{})
+	if !isMap {
+		return false
+	}
+
+	return len(retryMap) == 3 && retryMap["count"] == float64(3) && retryMap["backoff"] == "exponential" && retryMap["delay"] == "1m"
+}
+
+//Normalizes a policy json document so that documents describing the same policy compare equal.
+//The document can be wrapped in a policy field, as returned by the api.
+func normalizeIsmPolicyJson(policyJson string) (string, error) {
+	var policy map[string]interface{}
+	uErr := json.Unmarshal([]byte(policyJson), &policy)
+	if uErr != nil {
+		return "", uErr
+	}
+
+	wrapped, isWrapped := policy["policy"].(map[string]interface{})
+	if isWrapped {
+		policy = wrapped
+	}
+
+	for _, field := range ismPolicyServerFields {
+		delete(policy, field)
+	}
+
+	for key, val := range policy {
+		if val == nil {
+			delete(policy, key)
+		}
+	}
+
+	//A single ism template is accepted in place of a list
+	ismTemplate, ismTemplateExists := policy["ism_template"]
+	if ismTemplateExists {
+		templates, isList := ismTemplate.([]interface{})
+		if !isList {
+			templates = []interface{}{ismTemplate}
+		}
+		for _, template := range templates {
+			templateMap, isMap := template.(map[string]interface{})
+			if isMap {
+				delete(templateMap, "last_updated_time")
 			}
-			stateElem["actions"] = actions
 		}
+		policy["ism_template"] = templates
+	}
 
-		if len(v.Transitions) > 0 {
-			transitions := make([]map[string]interface{}, 0)
-			for _, t := range v.Transitions {
-				transitionElem := map[string]interface{}{}
-	
-				transitionElem["state_name"] = t.StateName
-				
-				conditions := map[string]interface{}{}
+	states, _ := policy["states"].([]interface{})
+	for _, state := range states {
+		stateMap, isMap := state.(map[string]interface{})
+		if !isMap {
+			continue
+		}
 
-				c := t.Conditions
-				if c.MinIndexAge != "" {
-					conditions["min_index_age"] = c.MinIndexAge
-				}
+		for _, field := range []string{"actions", "transitions"} {
+			if stateMap[field] == nil {
+				stateMap[field] = []interface{}{}
+			}
+		}
 
-				if c.MinDocCount > 0 {
-					conditions["min_doc_count"] = c.MinDocCount
-				}
+		actions, _ := stateMap["actions"].([]interface{})
+		for _, action := range actions {
+			actionMap, isMap := action.(map[string]interface{})
+			if isMap && isDefaultIsmRetry(actionMap["retry"]) {
+				delete(actionMap, "retry")
+			}
+		}
+	}
 
-				if c.MinSize != "" {
-					conditions["min_size"] = c.MinSize
-				}
+	normalized, marErr := json.Marshal(policy)
+	if marErr != nil {
+		return "", marErr
+	}
+
+	return string(normalized), nil
+}
 
-				transitionElem["conditions"] = []map[string]interface{}{conditions}
-	
-				transitions = append(transitions, transitionElem)
+//Checks whether all the fields of a json value are present with the same values in another one
+func isJsonSubset(sub interface{}, super interface{}) bool {
+	switch typedSub := sub.(type) {
+	case map[string]interface{}:
+		typedSuper, isMap := super.(map[string]interface{})
+		if !isMap {
+			return false
+		}
+		for key, val := range typedSub {
+			superVal, exists := typedSuper[key]
+			if !exists || !isJsonSubset(val, superVal) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		typedSuper, isList := super.([]interface{})
+		if !isList || len(typedSub) != len(typedSuper) {
+			return false
+		}
+		for idx, _ := range typedSub {
+			if !isJsonSubset(typedSub[idx], typedSuper[idx]) {
+				return false
 			}
-			stateElem["transitions"] = transitions
 		}
+		return true
+	}
 
-		states = append(states, stateElem)
+	return reflect.DeepEqual(sub, super)
+}
+
+
+//Opensearch fills in the default values of many of the fields of the policy, which would show up as differences with the configured document.
+//The configured document is kept if all its fields match the policy in opensearch, so fields added outside of terraform are not detected.
+func writeIsmPolicyJsonToResourceModel(state *ismPolicyResourceModel, policyJson json.RawMessage) error {
+	normalized, normErr := normalizeIsmPolicyJson(string(policyJson))
+	if normErr != nil {
+		return normErr
 	}
-	d.Set("states", states)
-}
\ No newline at end of file
+
+	normalizedPrevious, prevNormErr := normalizeIsmPolicyJson(state.PolicyJson.ValueString())
+	if prevNormErr == nil {
+		var prevParsed interface{}
+		var parsed interface{}
+		_ = json.Unmarshal([]byte(normalizedPrevious), &prevParsed)
+		_ = json.Unmarshal([]byte(normalized), &parsed)
+		if isJsonSubset(prevParsed, parsed) {
+			return nil
+		}
+	}
+
+	state.PolicyJson = types.StringValue(normalized)
+	return nil
+}
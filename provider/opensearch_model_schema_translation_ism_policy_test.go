@@ -0,0 +1,373 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+func TestWriteIsmPolicyModelToResourceModelWithoutPreviousState(t *testing.T) {
+	state := ismPolicyResourceModel{}
+	policy := IsmPolicyModel{
+		Description:  "imported policy",
+		DefaultState: "hot",
+		States: []IsmPolicyStateModel{
+			{
+				Name: "hot",
+				Actions: []IsmPsActionModel{
+					{
+						Retry:    &IsmPsaRetryModel{Count: 3, Backoff: "exponential", Delay: "1m"},
+						ReadOnly: &EmptyModel{},
+					},
+					{
+						Delete: &EmptyModel{},
+					},
+				},
+				Transitions: []IsmPsTransitionModel{},
+			},
+		},
+	}
+
+	diags := writeIsmPolicyModelToResourceModel(context.Background(), &policy, &state)
+	if diags.HasError() {
+		t.Fatalf("Failed to write the policy: %v", diags)
+	}
+
+	if state.DefaultState.ValueString() != "hot" {
+		t.Errorf("Expected default state 'hot', got '%s'", state.DefaultState.ValueString())
+	}
+
+	if len(state.States) != 1 {
+		t.Fatalf("Expected 1 state, got %d", len(state.States))
+	}
+
+	actions := state.States[0].Actions
+	if len(actions) != 2 {
+		t.Fatalf("Expected 2 actions, got %d", len(actions))
+	}
+
+	if actions[0].Retry == nil || actions[0].Retry.Count.ValueInt64() != 3 {
+		t.Errorf("Expected the retry of the first action to be kept, got %v", actions[0].Retry)
+	}
+
+	if state.IsmTemplate == nil || state.States[0].Transitions == nil {
+		t.Errorf("Expected the nested blocks to be empty rather than null")
+	}
+}
+
+func TestIsmRolloverMinDocCountNullSemantics(t *testing.T) {
+	tests := []struct {
+		name        string
+		minDocCount types.Int64
+		json        string
+	}{
+		{name: "unset", minDocCount: types.Int64Null(), json: `{"copy_alias":true}`},
+		{name: "zero", minDocCount: types.Int64Value(0), json: `{"min_doc_count":0,"copy_alias":true}`},
+		{name: "positive", minDocCount: types.Int64Value(100), json: `{"min_doc_count":100,"copy_alias":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action := ismActionResourceModel{
+				Action: types.StringValue("rollover"),
+				Rollover: &ismRolloverResourceModel{
+					MinDocCount: tt.minDocCount,
+					CopyAlias:   types.BoolValue(true),
+				},
+			}
+
+			model, diags := ismActionResourceModelToModel(context.Background(), action)
+			if diags.HasError() {
+				t.Fatalf("Failed to convert the action: %v", diags)
+			}
+
+			rolloverJson, _ := json.Marshal(model.Rollover)
+			if string(rolloverJson) != tt.json {
+				t.Errorf("Expected '%s', got '%s'", tt.json, string(rolloverJson))
+			}
+
+			written, diags := ismActionModelToResourceModel(context.Background(), model, action)
+			if diags.HasError() {
+				t.Fatalf("Failed to convert the action back: %v", diags)
+			}
+
+			if !written.Rollover.MinDocCount.Equal(tt.minDocCount) {
+				t.Errorf("Expected min_doc_count %s, got %s", tt.minDocCount.String(), written.Rollover.MinDocCount.String())
+			}
+		})
+	}
+}
+
+func TestUpgradeIsmPolicySdkState(t *testing.T) {
+	ctx := context.Background()
+	ismPolicy := NewIsmPolicyResource().(*ismPolicyResource)
+
+	schemaResp := resource.SchemaResponse{}
+	ismPolicy.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	sdkState := `{
+		"id": "logs",
+		"policy_id": "logs",
+		"policy_json": "",
+		"description": "logs policy",
+		"default_state": "hot",
+		"switched_managed_indices": 0,
+		"ism_template": [{"priority": 0, "index_patterns": ["logs-*"]}],
+		"error_notification": [],
+		"update_managed_indices": [],
+		"states": [
+			{
+				"name": "hot",
+				"actions": [
+					{
+						"timeout": "",
+						"retry": [{"count": 5, "backoff": "constant", "delay": ""}],
+						"action": "rollover",
+						"index_priority": 0,
+						"replica_count": 0,
+						"rollover": [{"min_size": "5gb", "min_primary_shard_size": "", "min_doc_count": 0, "min_index_age": "", "copy_alias": false}],
+						"force_merge": [], "shrink": [], "allocation": [], "snapshot": [], "rollup": [], "notification": [], "transform": []
+					},
+					{
+						"timeout": "",
+						"retry": [],
+						"action": "index_priority",
+						"index_priority": 0,
+						"replica_count": 0,
+						"rollover": [], "force_merge": [], "shrink": [], "allocation": [], "snapshot": [], "rollup": [], "notification": [], "transform": []
+					}
+				],
+				"transitions": [
+					{
+						"state_name": "delete",
+						"conditions": [{"min_index_age": "30d", "min_rollover_age": "", "min_doc_count": 0, "min_size": "", "cron": []}]
+					}
+				]
+			},
+			{
+				"name": "delete",
+				"actions": [],
+				"transitions": []
+			}
+		],
+		"timeouts": null
+	}`
+
+	req := resource.UpgradeStateRequest{
+		RawState: &tfprotov6.RawState{JSON: []byte(sdkState)},
+	}
+	resp := resource.UpgradeStateResponse{
+		State: tfsdk.State{Schema: schemaResp.Schema},
+	}
+	ismPolicy.UpgradeState(ctx)[1].StateUpgrader(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Failed to upgrade the state: %v", resp.Diagnostics)
+	}
+
+	state := ismPolicyResourceModel{}
+	diags := resp.State.Get(ctx, &state)
+	if diags.HasError() {
+		t.Fatalf("Failed to read the upgraded state: %v", diags)
+	}
+
+	if !state.PolicyJson.IsNull() {
+		t.Errorf("Expected policy_json to be null, got %s", state.PolicyJson.String())
+	}
+
+	if len(state.IsmTemplate) != 1 || state.IsmTemplate[0].Priority.IsNull() || state.IsmTemplate[0].Priority.ValueInt64() != 0 {
+		t.Errorf("Expected the priority of the ism template to keep its default of 0, got %v", state.IsmTemplate)
+	}
+
+	rollover := state.States[0].Actions[0]
+	if rollover.Retry == nil || rollover.Retry.Count.ValueInt64() != 5 || !rollover.Retry.Delay.IsNull() {
+		t.Errorf("Expected the retry to be an object with a null delay, got %v", rollover.Retry)
+	}
+
+	if rollover.Rollover == nil || rollover.Rollover.MinSize.ValueString() != "5gb" || !rollover.Rollover.MinDocCount.IsNull() {
+		t.Errorf("Expected the rollover to be an object with a null min_doc_count, got %v", rollover.Rollover)
+	}
+
+	if !rollover.IndexPriority.IsNull() || rollover.ForceMerge != nil {
+		t.Errorf("Expected the arguments of other actions to be null")
+	}
+
+	if state.States[0].Actions[1].IndexPriority.ValueInt64() != 0 || state.States[0].Actions[1].IndexPriority.IsNull() {
+		t.Errorf("Expected the priority of the index_priority action to be 0, got %s", state.States[0].Actions[1].IndexPriority.String())
+	}
+
+	conditions := state.States[0].Transitions[0].Conditions
+	if conditions == nil || conditions.MinIndexAge.ValueString() != "30d" || !conditions.MinDocCount.IsNull() || conditions.Cron != nil {
+		t.Errorf("Expected the conditions to be an object with a null min_doc_count, got %v", conditions)
+	}
+
+	if state.States[1].Actions == nil || len(state.States[1].Actions) != 0 {
+		t.Errorf("Expected the actions of the delete state to be empty, got %v", state.States[1].Actions)
+	}
+
+	if state.SwitchedManagedIndices.ValueInt64() != 0 || state.SwitchedManagedIndices.IsNull() {
+		t.Errorf("Expected switched_managed_indices to be 0, got %s", state.SwitchedManagedIndices.String())
+	}
+}
+
+func TestGetDefaultRetriesAdjustedActions(t *testing.T) {
+	defaultRetry := &IsmPsaRetryModel{Count: 3, Backoff: "exponential", Delay: "1m"}
+	customRetry := &IsmPsaRetryModel{Count: 5, Backoff: "constant", Delay: "10m"}
+
+	tests := []struct {
+		name          string
+		prev          *IsmPolicyStateModel
+		next          IsmPolicyStateModel
+		expectedRetry []*IsmPsaRetryModel
+	}{
+		{
+			name:          "no previous state",
+			prev:          nil,
+			next:          IsmPolicyStateModel{Actions: []IsmPsActionModel{{Retry: defaultRetry}}},
+			expectedRetry: []*IsmPsaRetryModel{defaultRetry},
+		},
+		{
+			name:          "default retry trimmed when absent before",
+			prev:          &IsmPolicyStateModel{Actions: []IsmPsActionModel{{}}},
+			next:          IsmPolicyStateModel{Actions: []IsmPsActionModel{{Retry: defaultRetry}}},
+			expectedRetry: []*IsmPsaRetryModel{nil},
+		},
+		{
+			name:          "custom retry kept",
+			prev:          &IsmPolicyStateModel{Actions: []IsmPsActionModel{{}}},
+			next:          IsmPolicyStateModel{Actions: []IsmPsActionModel{{Retry: customRetry}}},
+			expectedRetry: []*IsmPsaRetryModel{customRetry},
+		},
+		{
+			name:          "missing retry in the next state",
+			prev:          &IsmPolicyStateModel{Actions: []IsmPsActionModel{{}}},
+			next:          IsmPolicyStateModel{Actions: []IsmPsActionModel{{}}},
+			expectedRetry: []*IsmPsaRetryModel{nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := tt.prev.GetDefaultRetriesAdjustedActions(&tt.next)
+			if len(actions) != len(tt.expectedRetry) {
+				t.Fatalf("Expected %d actions, got %d", len(tt.expectedRetry), len(actions))
+			}
+			for idx, action := range actions {
+				if action.Retry != tt.expectedRetry[idx] {
+					t.Errorf("Action %d: expected retry %v, got %v", idx, tt.expectedRetry[idx], action.Retry)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeIsmPolicyJson(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    string
+		second   string
+		expected bool
+	}{
+		{
+			name:     "wrapped in a policy field",
+			first:    `{"description":"demo","default_state":"hot","states":[]}`,
+			second:   `{"policy":{"description":"demo","default_state":"hot","states":[]}}`,
+			expected: true,
+		},
+		{
+			name:     "fields added by opensearch",
+			first:    `{"description":"demo","default_state":"hot","states":[]}`,
+			second:   `{"policy_id":"demo","last_updated_time":1,"schema_version":17,"user":null,"error_notification":null,"description":"demo","default_state":"hot","states":[]}`,
+			expected: true,
+		},
+		{
+			name:     "single ism template",
+			first:    `{"ism_template":{"index_patterns":["logs-*"],"priority":1}}`,
+			second:   `{"ism_template":[{"index_patterns":["logs-*"],"priority":1,"last_updated_time":1}]}`,
+			expected: true,
+		},
+		{
+			name:     "missing actions and transitions",
+			first:    `{"states":[{"name":"hot"}]}`,
+			second:   `{"states":[{"name":"hot","actions":[],"transitions":[]}]}`,
+			expected: true,
+		},
+		{
+			name:     "default retry",
+			first:    `{"states":[{"name":"hot","actions":[{"read_only":{}}],"transitions":[]}]}`,
+			second:   `{"states":[{"name":"hot","actions":[{"read_only":{},"retry":{"count":3,"backoff":"exponential","delay":"1m"}}],"transitions":[]}]}`,
+			expected: true,
+		},
+		{
+			name:     "custom retry",
+			first:    `{"states":[{"name":"hot","actions":[{"read_only":{}}],"transitions":[]}]}`,
+			second:   `{"states":[{"name":"hot","actions":[{"read_only":{},"retry":{"count":5,"backoff":"exponential","delay":"1m"}}],"transitions":[]}]}`,
+			expected: false,
+		},
+		{
+			name:     "different description",
+			first:    `{"description":"demo"}`,
+			second:   `{"description":"other"}`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, firstErr := normalizeIsmPolicyJson(tt.first)
+			if firstErr != nil {
+				t.Fatalf("Failed to normalize '%s': %s", tt.first, firstErr.Error())
+			}
+			second, secondErr := normalizeIsmPolicyJson(tt.second)
+			if secondErr != nil {
+				t.Fatalf("Failed to normalize '%s': %s", tt.second, secondErr.Error())
+			}
+
+			if (first == second) != tt.expected {
+				t.Errorf("Expected equality to be %t, got '%s' and '%s'", tt.expected, first, second)
+			}
+		})
+	}
+
+	_, err := normalizeIsmPolicyJson("not json")
+	if err == nil {
+		t.Errorf("Expected an error for an invalid document")
+	}
+}
+
+func TestIsJsonSubset(t *testing.T) {
+	tests := []struct {
+		name     string
+		sub      string
+		super    string
+		expected bool
+	}{
+		{name: "identical", sub: `{"a":1}`, super: `{"a":1}`, expected: true},
+		{name: "extra field", sub: `{"a":1}`, super: `{"a":1,"b":2}`, expected: true},
+		{name: "nested extra field", sub: `{"a":{"b":1}}`, super: `{"a":{"b":1,"c":2}}`, expected: true},
+		{name: "extra field in list element", sub: `{"a":[{"b":1}]}`, super: `{"a":[{"b":1,"c":2}]}`, expected: true},
+		{name: "missing field", sub: `{"a":1,"b":2}`, super: `{"a":1}`, expected: false},
+		{name: "different value", sub: `{"a":1}`, super: `{"a":2}`, expected: false},
+		{name: "different type", sub: `{"a":"1"}`, super: `{"a":1}`, expected: false},
+		{name: "different list length", sub: `{"a":[1]}`, super: `{"a":[1,2]}`, expected: false},
+		{name: "different list order", sub: `{"a":[1,2]}`, super: `{"a":[2,1]}`, expected: false},
+		{name: "map against list", sub: `{"a":{}}`, super: `{"a":[]}`, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sub interface{}
+			var super interface{}
+			_ = json.Unmarshal([]byte(tt.sub), &sub)
+			_ = json.Unmarshal([]byte(tt.super), &super)
+
+			if isJsonSubset(sub, super) != tt.expected {
+				t.Errorf("Expected '%s' subset of '%s' to be %t", tt.sub, tt.super, tt.expected)
+			}
+		})
+	}
+}
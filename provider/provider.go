@@ -1,17 +1,24 @@
 package provider
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
-	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"net/url"
 	"math/rand"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func init() {
@@ -34,30 +41,166 @@ func Provider() *schema.Provider {
 				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("OPENSEARCH_PASSWORD", ""),
 			},
+			"token": &schema.Schema{
+				Description: "Bearer token to authenticate with, typically a jwt for opensearch clusters using openid connect or jwt authentication. Can alternatively be set with the OPENSEARCH_TOKEN environment variable.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("OPENSEARCH_TOKEN", ""),
+			},
+			"token_file": &schema.Schema{
+				Description: "File that contains a bearer token to authenticate with. The file is read again for every request so that short-lived tokens can be rotated while the provider runs. Can alternatively be set with the OPENSEARCH_TOKEN_FILE environment variable.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("OPENSEARCH_TOKEN_FILE", ""),
+			},
+			"api_key": &schema.Schema{
+				Description: "Api key to authenticate with. It is passed in an `Authorization: ApiKey <api_key>` header. Can alternatively be set with the OPENSEARCH_API_KEY environment variable.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("OPENSEARCH_API_KEY", ""),
+			},
 			"ca_cert": &schema.Schema{
-				Description: "File that contains the CA certificate that signed the opensearch servers' certificates. Can alternatively be set with the OPENSEARCH_CACERT environment variable. Can also be omitted.",
+				Description: "CA certificate that signed the opensearch servers' certificates. Can be either a file path or the pem content directly and can contain several certificates. Can alternatively be set with the OPENSEARCH_CACERT environment variable. Can also be omitted.",
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OPENSEARCH_CACERT", ""),
 			},
 			"client_cert": &schema.Schema{
-				Description: "File that contains the client certificate used to authentify the user. Can alternatively be set with the OPENSEARCH_CERT environment variable. Can be omitted if password authentication is used.",
+				Description: "Client certificate used to authentify the user. Can be either a file path or the pem content directly. Can alternatively be set with the OPENSEARCH_CERT environment variable. Can be omitted if password authentication is used.",
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OPENSEARCH_CERT", ""),
 			},
 			"client_key": &schema.Schema{
-				Description: "File that contains the client encryption key used to authentify the user. Can alternatively be set with the OPENSEARCH_KEY environment variable. Can be omitted if password authentication is used.",
+				Description: "Client private key used to authentify the user. Can be either a file path or the pem content directly. Can alternatively be set with the OPENSEARCH_KEY environment variable. Can be omitted if password authentication is used.",
 				Type:        schema.TypeString,
 				Optional:    true,
+				Sensitive:   true,
 				DefaultFunc: schema.EnvDefaultFunc("OPENSEARCH_KEY", ""),
 			},
+			"client_key_password": &schema.Schema{
+				Description: "Password to decrypt the client private key with if it is encrypted. Both pkcs8 and legacy openssl encryption are supported. Can alternatively be set with the OPENSEARCH_KEY_PASSWORD environment variable.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("OPENSEARCH_KEY_PASSWORD", ""),
+			},
+			"tls_server_name": &schema.Schema{
+				Description: "Server name to validate the opensearch servers' certificates against instead of the hostname of the endpoints. Useful when connecting to the servers through ip addresses or tunnels.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"insecure_skip_verify": &schema.Schema{
+				Description: "If set to true, the opensearch servers' certificates will not be validated. This is insecure and should only be used with development clusters. Defaults to false.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"aws_auth": &schema.Schema{
+				Description: "Authenticate the requests with aws signature version 4 for amazon opensearch service domains or opensearch serverless collections. Credentials are resolved from the static keys if specified, else from the profile if specified, else from the default aws credentials chain. Cannot be combined with other authentication modes.",
+				Type:        schema.TypeSet,
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"region": {
+							Description:  "Aws region of the domain.",
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotEmpty,
+						},
+						"service": {
+							Description: "Aws service to sign the requests for. Can be: es for amazon opensearch service domains and aoss for opensearch serverless collections. Defaults to es.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "es",
+							ValidateFunc: validation.StringInSlice(
+								[]string{
+									"es",
+									"aoss",
+								},
+								false,
+							),
+						},
+						"access_key": {
+							Description: "Aws access key id.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"secret_key": {
+							Description: "Aws secret access key.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"session_token": {
+							Description: "Aws session token to use along with temporary static keys.",
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+						},
+						"profile": {
+							Description: "Profile of the aws shared configuration to take the credentials from.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"assume_role_arn": {
+							Description: "Arn of a role to assume with the resolved credentials, or with the web identity token if web_identity_token_file is specified.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"assume_role_session_name": {
+							Description: "Session name to use when assuming a role.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+						"web_identity_token_file": {
+							Description: "File containing an oidc token to assume the role specified in assume_role_arn with. The file is read again each time the credentials are refreshed.",
+							Type:        schema.TypeString,
+							Optional:    true,
+						},
+					},
+				},
+			},
 			"endpoints": &schema.Schema{
 				Description: "Endpoints of the opensearch servers. The entry of each server should follow the http|https://ip:port format and be coma separated. Can alternatively be set with the OPENSEARCH_ENDPOINTS environment variable.",
 				Type:        schema.TypeString,
 				Required:    true,
 				DefaultFunc: schema.EnvDefaultFunc("OPENSEARCH_ENDPOINTS", ""),
 			},
+			"proxy_url": &schema.Schema{
+				Description: "Url of the proxy to reach the opensearch servers through. Supports the http, https and socks5 schemes. Defaults to the proxy specified by the HTTPS_PROXY and HTTP_PROXY environment variables, honoring NO_PROXY.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				ValidateFunc: validation.IsURLWithScheme(
+					[]string{
+						"http",
+						"https",
+						"socks5",
+					},
+				),
+			},
+			"proxy_username": &schema.Schema{
+				Description: "Username to authenticate with on the proxy.",
+				Type:        schema.TypeString,
+				Optional:    true,
+			},
+			"proxy_password": &schema.Schema{
+				Description: "Password to authenticate with on the proxy.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"headers": &schema.Schema{
+				Description: "Additional http headers to add to every request made to the opensearch servers.",
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"connection_timeout": &schema.Schema{
 				Description: "Timeout to establish the opensearch servers connection in golang duration format. Defaults to 10 seconds.",
 				Type:        schema.TypeString,
@@ -89,54 +232,260 @@ func Provider() *schema.Provider {
 				},
 			},
 			"retries": &schema.Schema{
-				Description: "Number of times operations that result in retriable errors should be re-attempted. Defaults to 10.",
-				Type:        schema.TypeInt,
+				Description:  "Number of times operations that result in retriable errors should be re-attempted. Defaults to 10.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"retry_min_delay": &schema.Schema{
+				Description: "Delay to wait before the first retry of a failed request in golang duration format. The delay doubles on each subsequent retry, with some random jitter, until it reaches retry_max_delay. Defaults to 1 second.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "1s",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					_, err := time.ParseDuration(v)
+					if err != nil {
+						return []string{}, []error{errors.New("retry_min_delay must be a value golang duration string value")}
+					}
+
+					return []string{}, []error{}
+				},
+			},
+			"retry_max_delay": &schema.Schema{
+				Description: "Maximum delay to wait between retries of a failed request in golang duration format. Also caps the wait requested by the servers with a Retry-After header. Defaults to 30 seconds.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "30s",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					_, err := time.ParseDuration(v)
+					if err != nil {
+						return []string{}, []error{errors.New("retry_max_delay must be a value golang duration string value")}
+					}
+
+					return []string{}, []error{}
+				},
+			},
+			"endpoint_failure_threshold": &schema.Schema{
+				Description: "Number of consecutive failed requests after which an endpoint is considered unhealthy. Unhealthy endpoints are skipped by all operations until endpoint_cooldown elapses, after which a single request probes the endpoint to determine if it recovered. Defaults to 3.",
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"endpoint_cooldown": &schema.Schema{
+				Description: "Time during which an unhealthy endpoint is skipped before being probed again in golang duration format. Defaults to 30 seconds.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "30s",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					_, err := time.ParseDuration(v)
+					if err != nil {
+						return []string{}, []error{errors.New("endpoint_cooldown must be a value golang duration string value")}
+					}
+
+					return []string{}, []error{}
+				},
+			},
+			"sniff": &schema.Schema{
+				Description: "If set to true, the provider will discover the nodes of the cluster by querying the nodes http info api on the endpoints at configuration time. The endpoints will then only be used as seeds and requests will be sent to the http publish addresses of the discovered nodes instead, excluding dedicated cluster manager nodes. Defaults to false.",
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+			},
+			"sniff_interval": &schema.Schema{
+				Description: "If sniffing is enabled, interval after which the discovered nodes should be refreshed in golang duration format. The refresh runs in the background and falls back to the configured endpoints if none of the discovered nodes answer. Defaults to 0 which means that the nodes are discovered only once at configuration time.",
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "0s",
+				ValidateFunc: func(val interface{}, key string) (warns []string, errs []error) {
+					v := val.(string)
+					_, err := time.ParseDuration(v)
+					if err != nil {
+						return []string{}, []error{errors.New("sniff_interval must be a value golang duration string value")}
+					}
+
+					return []string{}, []error{}
+				},
+			},
+			"retry_on_status_codes": &schema.Schema{
+				Description: "Http status codes of error responses that should be retried. Defaults to 429, 502, 503 and 504. Connection errors and responses reporting rejected executions are always retried while 400, 401, 403 and 409 responses are never retried unless explicitly listed. Conflicts on writes conditioned on a sequence number are never retried as they would conflict again, the provider refreshes the sequence number instead.",
+				Type:        schema.TypeList,
 				Optional:    true,
-				Default:     10,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(400, 599),
+				},
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"opensearch_role": resourceOpensearchRole(),
-			"opensearch_user": resourceOpensearchUser(),
-			"opensearch_role_mapping": resourceOpensearchRoleMapping(),
-			"opensearch_ism_policy": resourceOpensearchIsmPolicy(),
+			"opensearch_ism_managed_index": resourceOpensearchIsmManagedIndex(),
+			"opensearch_ism_retry": resourceOpensearchIsmRetry(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
+			"opensearch_ism_explain": dataSourceOpensearchIsmExplain(),
 		},
-		ConfigureFunc: providerConfigure,
+		ConfigureContextFunc: providerConfigure,
+	}
+}
+
+//Uses the proxy from the environment if none is specified explicitly.
+//Proxy credentials are applied to whichever proxy ends up being used.
+func getProxyFunc(proxyUrl string, username string, password string) (func(*http.Request) (*url.URL, error), error) {
+	var proxy func(*http.Request) (*url.URL, error)
+	if proxyUrl != "" {
+		u, uErr := url.Parse(proxyUrl)
+		if uErr != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to parse proxy url: %s", uErr.Error()))
+		}
+		proxy = http.ProxyURL(u)
+	} else {
+		proxy = http.ProxyFromEnvironment
+	}
+
+	if username == "" {
+		return proxy, nil
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		u, err := proxy(req)
+		if err != nil || u == nil {
+			return u, err
+		}
+
+		withCreds := *u
+		withCreds.User = url.UserPassword(username, password)
+		return &withCreds, nil
+	}, nil
+}
+
+func awsAuthSchemaToConfig(d map[string]interface{}) AwsAuthConfig {
+	return AwsAuthConfig{
+		Region:               d["region"].(string),
+		Service:              d["service"].(string),
+		AccessKey:            d["access_key"].(string),
+		SecretKey:            d["secret_key"].(string),
+		SessionToken:         d["session_token"].(string),
+		Profile:              d["profile"].(string),
+		AssumeRoleArn:        d["assume_role_arn"].(string),
+		SessionName:          d["assume_role_session_name"].(string),
+		WebIdentityTokenFile: d["web_identity_token_file"].(string),
+	}
+}
+
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	cli, err := getOpensearchClient(ctx, d)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	return cli, nil
+}
+
+//Settings of the provider, as read by the sdk or converted from the configuration received by the framework
+type providerSettings interface {
+	Get(key string) interface{}
+}
+
+//The sdk and framework providers are served by the same process and configured with the same settings,
+//so they share a single client and with it the health of the endpoints, the sniffer and the aws credentials
+var opensearchClients = struct {
+	sync.Mutex
+	clients map[string]OpensearchClient
+}{clients: map[string]OpensearchClient{}}
+
+func getOpensearchClient(ctx context.Context, d providerSettings) (OpensearchClient, error) {
+	key, keyErr := providerSettingsKey(d)
+	if keyErr != nil {
+		return OpensearchClient{}, keyErr
+	}
+
+	opensearchClients.Lock()
+	defer opensearchClients.Unlock()
+
+	cli, cached := opensearchClients.clients[key]
+	if cached {
+		return cli, nil
+	}
+
+	configured, err := configureOpensearchClient(ctx, d)
+	if err != nil {
+		return OpensearchClient{}, err
+	}
+
+	cli = configured.(OpensearchClient)
+	opensearchClients.clients[key] = cli
+	return cli, nil
+}
+
+//The settings are hashed so that the secrets they contain are not kept around a second time
+func providerSettingsKey(d providerSettings) (string, error) {
+	values := map[string]interface{}{}
+	for key := range Provider().Schema {
+		value := d.Get(key)
+		if set, isSet := value.(*schema.Set); isSet {
+			value = set.List()
+		}
+		values[key] = value
+	}
+
+	serialized, err := json.Marshal(values)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Failed to serialize the settings of the provider: %s", err.Error()))
 	}
+
+	hash := sha256.Sum256(serialized)
+	return hex.EncodeToString(hash[:]), nil
 }
 
-func providerConfigure(d *schema.ResourceData) (interface{}, error) {
+func configureOpensearchClient(ctx context.Context, d providerSettings) (interface{}, error) {
 	endpoints, _ := d.Get("endpoints").(string)
 	username, _ := d.Get("username").(string)
 	password, _ := d.Get("password").(string)
+	token, _ := d.Get("token").(string)
+	tokenFile, _ := d.Get("token_file").(string)
+	apiKey, _ := d.Get("api_key").(string)
 	caCert, _ := d.Get("ca_cert").(string)
 	cert, _ := d.Get("client_cert").(string)
 	key, _ := d.Get("client_key").(string)
+	keyPassword, _ := d.Get("client_key_password").(string)
+	tlsServerName, _ := d.Get("tls_server_name").(string)
+	insecureSkipVerify, _ := d.Get("insecure_skip_verify").(bool)
+	proxyUrl, _ := d.Get("proxy_url").(string)
+	proxyUsername, _ := d.Get("proxy_username").(string)
+	proxyPassword, _ := d.Get("proxy_password").(string)
+	headers, _ := d.Get("headers").(map[string]interface{})
 	connectionTimeout, _ := d.Get("connection_timeout").(string)
 	requestTimeout, _ := d.Get("request_timeout").(string)
 	retries, _ := d.Get("retries").(int)
-	tlsConf := &tls.Config{}
+	retryMinDelay, _ := d.Get("retry_min_delay").(string)
+	retryMaxDelay, _ := d.Get("retry_max_delay").(string)
+	retryOnStatusCodes, _ := d.Get("retry_on_status_codes").([]interface{})
+	endpointFailureThreshold, _ := d.Get("endpoint_failure_threshold").(int)
+	endpointCooldown, _ := d.Get("endpoint_cooldown").(string)
+	sniff, _ := d.Get("sniff").(bool)
+	sniffInterval, _ := d.Get("sniff_interval").(string)
+	awsAuth, _ := d.Get("aws_auth").(*schema.Set)
+	tlsConf := &tls.Config{
+		ServerName: tlsServerName,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
 
 	if cert != "" {
-		certData, err := tls.LoadX509KeyPair(cert, key)
+		certData, err := loadClientCertificate(cert, key, keyPassword)
 		if err != nil {
 			return nil, err
 		}
-		(*tlsConf).Certificates = []tls.Certificate{certData}
-		(*tlsConf).InsecureSkipVerify = false
+		(*tlsConf).Certificates = []tls.Certificate{*certData}
 	}
 
 	if caCert != "" {
-		caCertContent, err := ioutil.ReadFile(caCert)
+		roots, err := loadCaCertPool(caCert)
 		if err != nil {
-			return nil, errors.New(fmt.Sprintf("Failed to read root certificate file: %s", err.Error()))
-		}
-		roots := x509.NewCertPool()
-		ok := roots.AppendCertsFromPEM(caCertContent)
-		if !ok {
-			return nil, errors.New("Failed to parse root certificate authority")
+			return nil, err
 		}
 		(*tlsConf).RootCAs = roots
 	}
@@ -144,23 +493,125 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	pConnectionTimeout, _ := time.ParseDuration(connectionTimeout)
 	pRequestTimeout, _ := time.ParseDuration(requestTimeout)
 
+	retryPolicy := RetryPolicy{
+		RetryOnStatusCodes: GetDefaultRetryOnStatusCodes(),
+	}
+	retryPolicy.MinDelay, _ = time.ParseDuration(retryMinDelay)
+	retryPolicy.MaxDelay, _ = time.ParseDuration(retryMaxDelay)
+	if retryPolicy.MinDelay > retryPolicy.MaxDelay {
+		return nil, errors.New("retry_min_delay cannot be greater than retry_max_delay")
+	}
+	if len(retryOnStatusCodes) > 0 {
+		retryPolicy.RetryOnStatusCodes = []int64{}
+		for _, val := range retryOnStatusCodes {
+			retryPolicy.RetryOnStatusCodes = append(retryPolicy.RetryOnStatusCodes, int64(val.(int)))
+		}
+	}
+
+	proxy, proxyErr := getProxyFunc(proxyUrl, proxyUsername, proxyPassword)
+	if proxyErr != nil {
+		return nil, proxyErr
+	}
+
 	client := &http.Client{
 		Transport: &http.Transport{
+			Proxy: proxy,
 			TLSClientConfig: tlsConf,
 			TLSHandshakeTimeout: pConnectionTimeout,
 			ResponseHeaderTimeout: pRequestTimeout,
 		},
 	}
 
+	reqHeaders := make(map[string]string)
+	for key, val := range headers {
+		reqHeaders[key] = val.(string)
+	}
+
+	pEndpointCooldown, _ := time.ParseDuration(endpointCooldown)
+
 	arrEndpoints := strings.Split(endpoints, ",")
 	rand.Seed(time.Now().UnixNano())
 	rand.Shuffle(len(arrEndpoints), func(i, j int) { arrEndpoints[i], arrEndpoints[j] = arrEndpoints[j], arrEndpoints[i] })
 
-	return OpensearchClient{
+	authModes := []string{}
+	if username != "" || password != "" {
+		authModes = append(authModes, "username/password")
+	}
+	if awsAuth.Len() > 0 {
+		authModes = append(authModes, "aws_auth")
+	}
+	if token != "" {
+		authModes = append(authModes, "token")
+	}
+	if tokenFile != "" {
+		authModes = append(authModes, "token_file")
+	}
+	if apiKey != "" {
+		authModes = append(authModes, "api_key")
+	}
+	if len(authModes) > 1 {
+		return nil, errors.New(fmt.Sprintf("Only one authentication mode can be used, but the following were specified: %s", strings.Join(authModes, ", ")))
+	}
+
+	secrets := []string{password, token, apiKey, keyPassword, proxyPassword}
+	var awsSigner *AwsSigner
+	for _, val := range awsAuth.List() {
+		awsConf := awsAuthSchemaToConfig(val.(map[string]interface{}))
+		secrets = append(secrets, awsConf.SecretKey, awsConf.SessionToken)
+
+		signer, signerErr := NewAwsSigner(awsConf)
+		if signerErr != nil {
+			return nil, signerErr
+		}
+		awsSigner = signer
+	}
+
+	cli := OpensearchClient{
 		Client: client,
 		Endpoints: arrEndpoints,
 		Username: username,
 		Password: password,
+		Token: token,
+		TokenFile: tokenFile,
+		ApiKey: apiKey,
+		Headers: reqHeaders,
 		Retries: retries,
-	}, nil
+		RetryPolicy: retryPolicy,
+		Health: NewEndpointsHealth(endpointFailureThreshold, pEndpointCooldown),
+		AwsSigner: awsSigner,
+		Secrets: secrets,
+	}
+
+	if sniff {
+		seed, seedErr := url.Parse(arrEndpoints[0])
+		if seedErr != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to parse endpoint '%s': %s", arrEndpoints[0], seedErr.Error()))
+		}
+
+		sniffed, sniffErr := cli.GetRequestContext(ctx).SniffEndpoints(seed.Scheme)
+		if sniffErr != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to discover the nodes of the cluster: %s", sniffErr.Error()))
+		}
+		cli.Endpoints = sniffed
+
+		pSniffInterval, _ := time.ParseDuration(sniffInterval)
+		if pSniffInterval > 0 {
+			cli.Sniffer = NewEndpointsSniffer(pSniffInterval, seed.Scheme, arrEndpoints, sniffed)
+		}
+	}
+
+	//Detection is a best effort as the provider's user may not be allowed to list the plugins
+	//and opensearch serverless does not expose those apis
+	cluster, clusterErr := cli.DetectCluster(ctx)
+	if IsUnsupportedCluster(clusterErr) {
+		return nil, clusterErr
+	}
+
+	if clusterErr != nil {
+		tflog.Warn(ctx, fmt.Sprintf("Failed to detect the cluster's distribution, version and plugins, assuming opensearch with all plugins installed: %s", clusterErr.Error()))
+	} else {
+		cli.Cluster = cluster
+	}
+
+	return cli, nil
 }
@@ -0,0 +1,1112 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	pschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+//During the migration to the plugin framework, the provider is served by both the sdk and the framework through a mux.
+//Each of them is configured from the same configuration, and they share the client built from it.
+type frameworkProvider struct{}
+
+type frameworkProviderData struct {
+	client OpensearchClient
+}
+
+func NewFrameworkProvider() provider.Provider {
+	return &frameworkProvider{}
+}
+
+//The sdk provider fills the defaults of the provider configuration when it is validated while the framework returns it as is.
+//The mux rejects different prepared configurations, so only the one of the sdk provider is kept.
+//The mux also reports the diagnostics of both providers, so the ones of the arguments, which the sdk provider validates with the same functions, are not repeated.
+type frameworkProviderServer struct {
+	tfprotov6.ProviderServer
+}
+
+func (server frameworkProviderServer) ValidateProviderConfig(ctx context.Context, req *tfprotov6.ValidateProviderConfigRequest) (*tfprotov6.ValidateProviderConfigResponse, error) {
+	res, err := server.ProviderServer.ValidateProviderConfig(ctx, req)
+	if res != nil {
+		res.PreparedConfig = nil
+
+		diagnostics := []*tfprotov6.Diagnostic{}
+		for _, diagnostic := range res.Diagnostics {
+			if diagnostic.Attribute == nil {
+				diagnostics = append(diagnostics, diagnostic)
+			}
+		}
+		res.Diagnostics = diagnostics
+	}
+	return res, err
+}
+
+func NewFrameworkProviderServer() func() tfprotov6.ProviderServer {
+	newServer := providerserver.NewProtocol6(NewFrameworkProvider())
+	return func() tfprotov6.ProviderServer {
+		return frameworkProviderServer{ProviderServer: newServer()}
+	}
+}
+
+func (p *frameworkProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "opensearch"
+}
+
+//The mux requires the schema of the provider to be identical across servers, so it is derived from the one of the sdk provider
+func (p *frameworkProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	sdkSchema, err := Provider().GRPCProvider().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		resp.Diagnostics.AddError("Error retrieving the schema of the provider", err.Error())
+		return
+	}
+
+	attributes, blocks, err := sdkBlockToFrameworkProviderSchema(sdkSchema.Provider.Block, Provider().Schema)
+	if err != nil {
+		resp.Diagnostics.AddError("Error converting the schema of the provider", err.Error())
+		return
+	}
+
+	resp.Schema = pschema.Schema{
+		Attributes: attributes,
+		Blocks:     blocks,
+	}
+}
+
+func (p *frameworkProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	settings, err := newFrameworkProviderSettings(req.Config.Raw, Provider().Schema)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading the configuration of the provider", err.Error())
+		return
+	}
+
+	cli, err := getOpensearchClient(ctx, settings)
+	if err != nil {
+		resp.Diagnostics.AddError("Error configuring the provider", err.Error())
+		return
+	}
+
+	data := &frameworkProviderData{
+		client: cli,
+	}
+	resp.ResourceData = data
+	resp.DataSourceData = data
+}
+
+func (p *frameworkProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewRoleResource,
+		NewUserResource,
+		NewRoleMappingResource,
+		NewIsmPolicyResource,
+	}
+}
+
+func (p *frameworkProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{}
+}
+
+func (data *frameworkProviderData) GetClient() (OpensearchClient, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if data == nil {
+		diags.AddError("Unconfigured provider", "The provider must be configured before its resources are used")
+		return OpensearchClient{}, diags
+	}
+
+	return data.client, diags
+}
+
+//The configuration received by the framework is converted to the values the sdk would read from it,
+//with the same defaults, so that the client is configured identically by both
+type frameworkProviderSettings struct {
+	values map[string]interface{}
+}
+
+func (settings frameworkProviderSettings) Get(key string) interface{} {
+	return settings.values[key]
+}
+
+func newFrameworkProviderSettings(config tftypes.Value, sdkSchema map[string]*schema.Schema) (frameworkProviderSettings, error) {
+	values, err := tftypesToSdkValues(config, sdkSchema)
+	if err != nil {
+		return frameworkProviderSettings{}, err
+	}
+
+	return frameworkProviderSettings{values: values}, nil
+}
+
+func tftypesToSdkValues(value tftypes.Value, sdkSchema map[string]*schema.Schema) (map[string]interface{}, error) {
+	attributes := map[string]tftypes.Value{}
+	if value.IsKnown() && !value.IsNull() {
+		err := value.As(&attributes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	values := map[string]interface{}{}
+	for key, attrSchema := range sdkSchema {
+		attribute, ok := attributes[key]
+		if ok && attribute.IsKnown() && !attribute.IsNull() {
+			sdkValue, err := tftypesToSdkValue(attribute, attrSchema)
+			if err != nil {
+				return nil, fmt.Errorf("Attribute '%s': %s", key, err.Error())
+			}
+			values[key] = sdkValue
+			continue
+		}
+
+		defaultValue, err := attrSchema.DefaultValue()
+		if err != nil {
+			return nil, fmt.Errorf("Attribute '%s': %s", key, err.Error())
+		}
+		if defaultValue == nil {
+			defaultValue = sdkZeroValue(attrSchema)
+		}
+		values[key] = defaultValue
+	}
+
+	return values, nil
+}
+
+func tftypesToSdkValue(value tftypes.Value, attrSchema *schema.Schema) (interface{}, error) {
+	switch attrSchema.Type {
+	case schema.TypeBool:
+		var val bool
+		err := value.As(&val)
+		return val, err
+	case schema.TypeInt:
+		var val big.Float
+		err := value.As(&val)
+		intVal, _ := val.Int64()
+		return int(intVal), err
+	case schema.TypeFloat:
+		var val big.Float
+		err := value.As(&val)
+		floatVal, _ := val.Float64()
+		return floatVal, err
+	case schema.TypeList, schema.TypeSet:
+		elements := []tftypes.Value{}
+		err := value.As(&elements)
+		if err != nil {
+			return nil, err
+		}
+
+		items := []interface{}{}
+		for _, element := range elements {
+			var item interface{}
+			var itemErr error
+			switch elem := attrSchema.Elem.(type) {
+			case *schema.Resource:
+				item, itemErr = tftypesToSdkValues(element, elem.Schema)
+			case *schema.Schema:
+				item, itemErr = tftypesToSdkValue(element, elem)
+			default:
+				itemErr = fmt.Errorf("Unsupported element type %T", attrSchema.Elem)
+			}
+			if itemErr != nil {
+				return nil, itemErr
+			}
+			items = append(items, item)
+		}
+
+		if attrSchema.Type == schema.TypeSet {
+			return schema.NewSet(sdkSetHash(attrSchema), items), nil
+		}
+		return items, nil
+	case schema.TypeMap:
+		elements := map[string]tftypes.Value{}
+		err := value.As(&elements)
+		if err != nil {
+			return nil, err
+		}
+
+		elemSchema, ok := attrSchema.Elem.(*schema.Schema)
+		if !ok {
+			elemSchema = &schema.Schema{Type: schema.TypeString}
+		}
+
+		items := map[string]interface{}{}
+		for key, element := range elements {
+			item, itemErr := tftypesToSdkValue(element, elemSchema)
+			if itemErr != nil {
+				return nil, itemErr
+			}
+			items[key] = item
+		}
+		return items, nil
+	}
+
+	var val string
+	err := value.As(&val)
+	return val, err
+}
+
+func sdkSetHash(attrSchema *schema.Schema) schema.SchemaSetFunc {
+	if attrSchema.Set != nil {
+		return attrSchema.Set
+	}
+
+	if resource, ok := attrSchema.Elem.(*schema.Resource); ok {
+		return schema.HashResource(resource)
+	}
+	return schema.HashSchema(attrSchema.Elem.(*schema.Schema))
+}
+
+func sdkZeroValue(attrSchema *schema.Schema) interface{} {
+	switch attrSchema.Type {
+	case schema.TypeBool:
+		return false
+	case schema.TypeInt:
+		return 0
+	case schema.TypeFloat:
+		return 0.0
+	case schema.TypeList:
+		return []interface{}{}
+	case schema.TypeSet:
+		return schema.NewSet(sdkSetHash(attrSchema), []interface{}{})
+	case schema.TypeMap:
+		return map[string]interface{}{}
+	}
+
+	return ""
+}
+
+func getFrameworkProviderData(providerData any) (*frameworkProviderData, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	//The provider data is not set yet when the resources are validated
+	if providerData == nil {
+		return nil, diags
+	}
+
+	data, ok := providerData.(*frameworkProviderData)
+	if !ok {
+		diags.AddError("Unexpected provider data", fmt.Sprintf("Expected provider data of type *frameworkProviderData, got %T", providerData))
+		return nil, diags
+	}
+
+	return data, diags
+}
+
+func sdkTypeToFrameworkType(typ tftypes.Type) (attr.Type, error) {
+	switch {
+	case typ.Is(tftypes.String):
+		return types.StringType, nil
+	case typ.Is(tftypes.Bool):
+		return types.BoolType, nil
+	case typ.Is(tftypes.Number):
+		return types.NumberType, nil
+	case typ.Is(tftypes.List{}):
+		elemType, err := sdkTypeToFrameworkType(typ.(tftypes.List).ElementType)
+		if err != nil {
+			return nil, err
+		}
+		return types.ListType{ElemType: elemType}, nil
+	case typ.Is(tftypes.Set{}):
+		elemType, err := sdkTypeToFrameworkType(typ.(tftypes.Set).ElementType)
+		if err != nil {
+			return nil, err
+		}
+		return types.SetType{ElemType: elemType}, nil
+	case typ.Is(tftypes.Map{}):
+		elemType, err := sdkTypeToFrameworkType(typ.(tftypes.Map).ElementType)
+		if err != nil {
+			return nil, err
+		}
+		return types.MapType{ElemType: elemType}, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported type %s", typ.String())
+}
+
+//The arguments are validated with the validation functions of the sdk schema, if any
+func sdkAttributeToFrameworkProviderSchema(attribute *tfprotov5.SchemaAttribute, sdkSchema *schema.Schema) (pschema.Attribute, error) {
+	typ, err := sdkTypeToFrameworkType(attribute.Type)
+	if err != nil {
+		return nil, fmt.Errorf("Attribute '%s': %s", attribute.Name, err.Error())
+	}
+
+	description := ""
+	markdownDescription := ""
+	if attribute.DescriptionKind == tfprotov5.StringKindMarkdown {
+		markdownDescription = attribute.Description
+	} else {
+		description = attribute.Description
+	}
+
+	switch typ := typ.(type) {
+	case types.ListType:
+		return pschema.ListAttribute{
+			ElementType:         typ.ElemType,
+			Description:         description,
+			MarkdownDescription: markdownDescription,
+			Required:            attribute.Required,
+			Optional:            attribute.Optional,
+			Sensitive:           attribute.Sensitive,
+		}, nil
+	case types.SetType:
+		return pschema.SetAttribute{
+			ElementType:         typ.ElemType,
+			Description:         description,
+			MarkdownDescription: markdownDescription,
+			Required:            attribute.Required,
+			Optional:            attribute.Optional,
+			Sensitive:           attribute.Sensitive,
+		}, nil
+	case types.MapType:
+		return pschema.MapAttribute{
+			ElementType:         typ.ElemType,
+			Description:         description,
+			MarkdownDescription: markdownDescription,
+			Required:            attribute.Required,
+			Optional:            attribute.Optional,
+			Sensitive:           attribute.Sensitive,
+		}, nil
+	}
+
+	var validate schema.SchemaValidateFunc
+	if sdkSchema != nil {
+		validate = (*sdkSchema).ValidateFunc
+	}
+
+	switch typ {
+	case types.BoolType:
+		return pschema.BoolAttribute{
+			Description:         description,
+			MarkdownDescription: markdownDescription,
+			Required:            attribute.Required,
+			Optional:            attribute.Optional,
+			Sensitive:           attribute.Sensitive,
+		}, nil
+	case types.NumberType:
+		validators := []validator.Number{}
+		if validate != nil {
+			validators = append(validators, sdkNumberValidator{
+				validate:    validate,
+				isInt:       (*sdkSchema).Type == schema.TypeInt,
+				description: fmt.Sprintf("value must be a valid %s", attribute.Name),
+			})
+		}
+
+		return pschema.NumberAttribute{
+			Description:         description,
+			MarkdownDescription: markdownDescription,
+			Required:            attribute.Required,
+			Optional:            attribute.Optional,
+			Sensitive:           attribute.Sensitive,
+			Validators:          validators,
+		}, nil
+	}
+
+	validators := []validator.String{}
+	if validate != nil {
+		validators = append(validators, sdkStringValidator{
+			validate:    validate,
+			description: fmt.Sprintf("value must be a valid %s", attribute.Name),
+		})
+	}
+
+	return pschema.StringAttribute{
+		Description:         description,
+		MarkdownDescription: markdownDescription,
+		Required:            attribute.Required,
+		Optional:            attribute.Optional,
+		Sensitive:           attribute.Sensitive,
+		Validators:          validators,
+	}, nil
+}
+
+func sdkBlockToFrameworkProviderSchema(block *tfprotov5.SchemaBlock, sdkSchemas map[string]*schema.Schema) (map[string]pschema.Attribute, map[string]pschema.Block, error) {
+	attributes := map[string]pschema.Attribute{}
+	for _, attribute := range block.Attributes {
+		fwAttribute, err := sdkAttributeToFrameworkProviderSchema(attribute, sdkSchemas[attribute.Name])
+		if err != nil {
+			return nil, nil, err
+		}
+		attributes[attribute.Name] = fwAttribute
+	}
+
+	blocks := map[string]pschema.Block{}
+	for _, nested := range block.BlockTypes {
+		nestedSdkSchemas := map[string]*schema.Schema{}
+		if sdkSchema, ok := sdkSchemas[nested.TypeName]; ok {
+			if elem, isResource := (*sdkSchema).Elem.(*schema.Resource); isResource {
+				nestedSdkSchemas = elem.Schema
+			}
+		}
+
+		nestedAttributes, nestedBlocks, err := sdkBlockToFrameworkProviderSchema(nested.Block, nestedSdkSchemas)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		description := ""
+		markdownDescription := ""
+		if nested.Block.DescriptionKind == tfprotov5.StringKindMarkdown {
+			markdownDescription = nested.Block.Description
+		} else {
+			description = nested.Block.Description
+		}
+
+		nestedObject := pschema.NestedBlockObject{
+			Attributes: nestedAttributes,
+			Blocks:     nestedBlocks,
+		}
+
+		switch nested.Nesting {
+		case tfprotov5.SchemaNestedBlockNestingModeSet:
+			blocks[nested.TypeName] = pschema.SetNestedBlock{
+				NestedObject:        nestedObject,
+				Description:         description,
+				MarkdownDescription: markdownDescription,
+			}
+		case tfprotov5.SchemaNestedBlockNestingModeList:
+			blocks[nested.TypeName] = pschema.ListNestedBlock{
+				NestedObject:        nestedObject,
+				Description:         description,
+				MarkdownDescription: markdownDescription,
+			}
+		case tfprotov5.SchemaNestedBlockNestingModeSingle:
+			blocks[nested.TypeName] = pschema.SingleNestedBlock{
+				Attributes:          nestedAttributes,
+				Blocks:              nestedBlocks,
+				Description:         description,
+				MarkdownDescription: markdownDescription,
+			}
+		default:
+			return nil, nil, fmt.Errorf("Block '%s': unsupported nesting mode %s", nested.TypeName, nested.Nesting.String())
+		}
+	}
+
+	return attributes, blocks, nil
+}
+
+//Timeouts block that is compatible with the one the sdk generates for resources that define a default timeout,
+//and optionally timeouts of their own for some operations
+var frameworkTimeoutsAttrTypes = map[string]attr.Type{
+	"default": types.StringType,
+}
+
+type frameworkTimeoutsModel struct {
+	Default types.String `tfsdk:"default"`
+}
+
+func frameworkTimeoutsBlock(operations ...string) rschema.Block {
+	attributes := map[string]rschema.Attribute{}
+	for _, operation := range append([]string{"default"}, operations...) {
+		attributes[operation] = rschema.StringAttribute{
+			Optional:   true,
+			Validators: []validator.String{durationValidator{}},
+		}
+	}
+
+	return rschema.SingleNestedBlock{
+		Attributes: attributes,
+	}
+}
+
+func getFrameworkTimeout(ctx context.Context, timeouts types.Object, defaultTimeout time.Duration) (time.Duration, diag.Diagnostics) {
+	return getFrameworkOperationTimeout(ctx, timeouts, "default", defaultTimeout)
+}
+
+//As with the sdk, operations without a timeout of their own use the default timeout if it is specified
+func getFrameworkOperationTimeout(ctx context.Context, timeouts types.Object, operation string, operationTimeout time.Duration) (time.Duration, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if timeouts.IsNull() || timeouts.IsUnknown() {
+		return operationTimeout, diags
+	}
+
+	for _, key := range []string{operation, "default"} {
+		value, ok := timeouts.Attributes()[key].(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() {
+			continue
+		}
+
+		timeout, err := time.ParseDuration(value.ValueString())
+		if err != nil {
+			diags.AddError("Invalid timeout", err.Error())
+			return operationTimeout, diags
+		}
+
+		return timeout, diags
+	}
+
+	return operationTimeout, diags
+}
+
+type durationValidator struct{}
+
+func (v durationValidator) Description(ctx context.Context) string {
+	return "value must be a golang duration string"
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	_, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid duration", fmt.Sprintf("%s must be a golang duration string value: %s", req.Path.String(), err.Error()))
+	}
+}
+
+type stringNotEmptyValidator struct{}
+
+func (v stringNotEmptyValidator) Description(ctx context.Context) string {
+	return "value must not be empty"
+}
+
+func (v stringNotEmptyValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v stringNotEmptyValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if req.ConfigValue.ValueString() == "" {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid value", fmt.Sprintf("%s must not be empty", req.Path.String()))
+	}
+}
+
+//Validation functions of the sdk, so that the ported resources validate their arguments as before
+type sdkStringValidator struct {
+	validate    schema.SchemaValidateFunc
+	description string
+}
+
+func (v sdkStringValidator) Description(ctx context.Context) string {
+	return v.description
+}
+
+func (v sdkStringValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v sdkStringValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	_, errs := v.validate(req.ConfigValue.ValueString(), req.Path.String())
+	for _, err := range errs {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid value", err.Error())
+	}
+}
+
+func stringInSliceValidator(values ...string) sdkStringValidator {
+	return sdkStringValidator{
+		validate:    validation.StringInSlice(values, false),
+		description: fmt.Sprintf("value must be one of: %s", strings.Join(values, ", ")),
+	}
+}
+
+type sdkInt64Validator struct {
+	validate    schema.SchemaValidateFunc
+	description string
+}
+
+func (v sdkInt64Validator) Description(ctx context.Context) string {
+	return v.description
+}
+
+func (v sdkInt64Validator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v sdkInt64Validator) ValidateInt64(ctx context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	_, errs := v.validate(int(req.ConfigValue.ValueInt64()), req.Path.String())
+	for _, err := range errs {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid value", err.Error())
+	}
+}
+
+func int64AtLeastValidator(min int) sdkInt64Validator {
+	return sdkInt64Validator{
+		validate:    validation.IntAtLeast(min),
+		description: fmt.Sprintf("value must be at least %d", min),
+	}
+}
+
+//Numbers of the provider schema, which are integers or floats in the sdk schema
+type sdkNumberValidator struct {
+	validate    schema.SchemaValidateFunc
+	isInt       bool
+	description string
+}
+
+func (v sdkNumberValidator) Description(ctx context.Context) string {
+	return v.description
+}
+
+func (v sdkNumberValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v sdkNumberValidator) ValidateNumber(ctx context.Context, req validator.NumberRequest, resp *validator.NumberResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var value interface{}
+	if v.isInt {
+		intValue, _ := req.ConfigValue.ValueBigFloat().Int64()
+		value = int(intValue)
+	} else {
+		value, _ = req.ConfigValue.ValueBigFloat().Float64()
+	}
+
+	_, errs := v.validate(value, req.Path.String())
+	for _, err := range errs {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid value", err.Error())
+	}
+}
+
+type sdkFloat64Validator struct {
+	validate    schema.SchemaValidateFunc
+	description string
+}
+
+func (v sdkFloat64Validator) Description(ctx context.Context) string {
+	return v.description
+}
+
+func (v sdkFloat64Validator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v sdkFloat64Validator) ValidateFloat64(ctx context.Context, req validator.Float64Request, resp *validator.Float64Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	_, errs := v.validate(req.ConfigValue.ValueFloat64(), req.Path.String())
+	for _, err := range errs {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid value", err.Error())
+	}
+}
+
+type sdkSetElementsValidator struct {
+	validate    schema.SchemaValidateFunc
+	description string
+}
+
+func (v sdkSetElementsValidator) Description(ctx context.Context) string {
+	return v.description
+}
+
+func (v sdkSetElementsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v sdkSetElementsValidator) ValidateSet(ctx context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for _, element := range req.ConfigValue.Elements() {
+		value, ok := element.(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() {
+			continue
+		}
+
+		_, errs := v.validate(value.ValueString(), req.Path.String())
+		for _, err := range errs {
+			resp.Diagnostics.AddAttributeError(req.Path, "Invalid value", err.Error())
+		}
+	}
+}
+
+//The framework validates the content of single nested blocks even when they are omitted.
+//What they require is only checked if the block that contains it is specified.
+func isParentBlockSpecified(ctx context.Context, config tfsdk.Config, attrPath path.Path) bool {
+	var parent types.Object
+	diags := config.GetAttribute(ctx, attrPath.ParentPath(), &parent)
+	return !diags.HasError() && !parent.IsNull() && !parent.IsUnknown()
+}
+
+//Blocks cannot be marked as required nor limited in size by the framework's schema, unlike the sdk's lists and sets
+type requiredBlockValidator struct{}
+
+func (v requiredBlockValidator) Description(ctx context.Context) string {
+	return "block must be specified"
+}
+
+func (v requiredBlockValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v requiredBlockValidator) ValidateObject(ctx context.Context, req validator.ObjectRequest, resp *validator.ObjectResponse) {
+	if req.ConfigValue.IsNull() && isParentBlockSpecified(ctx, req.Config, req.Path) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Missing block", fmt.Sprintf("%s must be specified", req.Path.String()))
+	}
+}
+
+//Attributes of single nested blocks are optional in the schema for the same reason
+type requiredInBlockValidator struct{}
+
+func (v requiredInBlockValidator) Description(ctx context.Context) string {
+	return "value must be specified if the block is"
+}
+
+func (v requiredInBlockValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v requiredInBlockValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() && isParentBlockSpecified(ctx, req.Config, req.Path) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Missing argument", fmt.Sprintf("%s must be specified", req.Path.String()))
+	}
+}
+
+func (v requiredInBlockValidator) ValidateInt64(ctx context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsNull() && isParentBlockSpecified(ctx, req.Config, req.Path) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Missing argument", fmt.Sprintf("%s must be specified", req.Path.String()))
+	}
+}
+
+type listSizeAtLeastValidator struct {
+	min int
+}
+
+func (v listSizeAtLeastValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("list must contain at least %d elements", v.min)
+}
+
+func (v listSizeAtLeastValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+//Nested blocks are null rather than empty only if the block that contains them is omitted
+func (v listSizeAtLeastValidator) ValidateList(ctx context.Context, req validator.ListRequest, resp *validator.ListResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if len(req.ConfigValue.Elements()) < v.min {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid number of elements", fmt.Sprintf("%s must contain at least %d elements", req.Path.String(), v.min))
+	}
+}
+
+//Replaces the sdk's default values, which the framework does not support for attributes
+type stringDefaultModifier struct {
+	value string
+}
+
+func (m stringDefaultModifier) Description(ctx context.Context) string {
+	return fmt.Sprintf("defaults to %s", m.value)
+}
+
+func (m stringDefaultModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m stringDefaultModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.ConfigValue.IsNull() {
+		resp.PlanValue = types.StringValue(m.value)
+	}
+}
+
+type boolDefaultModifier struct {
+	value bool
+}
+
+func (m boolDefaultModifier) Description(ctx context.Context) string {
+	return fmt.Sprintf("defaults to %t", m.value)
+}
+
+func (m boolDefaultModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m boolDefaultModifier) PlanModifyBool(ctx context.Context, req planmodifier.BoolRequest, resp *planmodifier.BoolResponse) {
+	if req.ConfigValue.IsNull() {
+		resp.PlanValue = types.BoolValue(m.value)
+	}
+}
+
+type int64DefaultModifier struct {
+	value int64
+}
+
+func (m int64DefaultModifier) Description(ctx context.Context) string {
+	return fmt.Sprintf("defaults to %d", m.value)
+}
+
+func (m int64DefaultModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m int64DefaultModifier) PlanModifyInt64(ctx context.Context, req planmodifier.Int64Request, resp *planmodifier.Int64Response) {
+	if req.ConfigValue.IsNull() {
+		resp.PlanValue = types.Int64Value(m.value)
+	}
+}
+
+func frameworkSetToStrings(ctx context.Context, set types.Set) ([]string, diag.Diagnostics) {
+	values := []string{}
+	if set.IsNull() || set.IsUnknown() {
+		return values, nil
+	}
+
+	diags := set.ElementsAs(ctx, &values, false)
+	return values, diags
+}
+
+//Empty values returned by the api are kept null if they were null before so that unset arguments do not produce diffs
+func stringsToFrameworkSet(ctx context.Context, values []string, prior types.Set) (types.Set, diag.Diagnostics) {
+	if len(values) == 0 && (prior.IsNull() || prior.IsUnknown()) {
+		return types.SetNull(types.StringType), nil
+	}
+
+	if values == nil {
+		values = []string{}
+	}
+	return types.SetValueFrom(ctx, types.StringType, values)
+}
+
+func stringToFrameworkString(value string, prior types.String) types.String {
+	if value == "" && (prior.IsNull() || prior.IsUnknown()) {
+		return types.StringNull()
+	}
+
+	return types.StringValue(value)
+}
+
+func int64ToFrameworkInt64(value int64, prior types.Int64) types.Int64 {
+	if value == 0 && (prior.IsNull() || prior.IsUnknown()) {
+		return types.Int64Null()
+	}
+
+	return types.Int64Value(value)
+}
+
+func float64ToFrameworkFloat64(value float64, prior types.Float64) types.Float64 {
+	if value == 0 && (prior.IsNull() || prior.IsUnknown()) {
+		return types.Float64Null()
+	}
+
+	return types.Float64Value(value)
+}
+
+func frameworkMapToStrings(ctx context.Context, m types.Map) (map[string]string, diag.Diagnostics) {
+	values := map[string]string{}
+	if m.IsNull() || m.IsUnknown() {
+		return values, nil
+	}
+
+	diags := m.ElementsAs(ctx, &values, false)
+	return values, diags
+}
+
+func stringsToFrameworkMap(ctx context.Context, values map[string]string, prior types.Map) (types.Map, diag.Diagnostics) {
+	if len(values) == 0 && (prior.IsNull() || prior.IsUnknown()) {
+		return types.MapNull(types.StringType), nil
+	}
+
+	if values == nil {
+		values = map[string]string{}
+	}
+	return types.MapValueFrom(ctx, types.StringType, values)
+}
+
+//Resources ported from the sdk are at version 1 of their schema. The state written by the sdk at version 0 has the same shape,
+//but it stores empty values where the framework expects null values for unset arguments
+func getSdkState(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse, target any) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	raw, err := req.RawState.Unmarshal(resp.State.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		diags.AddError("Error reading the state written by the sdk", err.Error())
+		return diags
+	}
+
+	state := tfsdk.State{
+		Schema: resp.State.Schema,
+		Raw:    raw,
+	}
+	diags.Append(state.Get(ctx, target)...)
+	return diags
+}
+
+func sdkSetToFrameworkSet(ctx context.Context, set types.Set) types.Set {
+	if !set.IsUnknown() && len(set.Elements()) == 0 {
+		return types.SetNull(set.ElementType(ctx))
+	}
+
+	return set
+}
+
+func sdkStringToFrameworkString(value types.String) types.String {
+	if value.ValueString() == "" {
+		return types.StringNull()
+	}
+
+	return value
+}
+
+func sdkTimeoutsToFrameworkTimeouts(ctx context.Context, timeouts types.Object) (types.Object, diag.Diagnostics) {
+	if timeouts.IsNull() || timeouts.IsUnknown() {
+		return timeouts, nil
+	}
+
+	model := frameworkTimeoutsModel{}
+	diags := timeouts.As(ctx, &model, types.ObjectAsOptions{})
+	if !diags.HasError() && model.Default.IsNull() {
+		return types.ObjectNull(frameworkTimeoutsAttrTypes), diags
+	}
+
+	return timeouts, diags
+}
+
+//The sdk stores the blocks limited to a single element as lists and the unset arguments as empty values.
+//A state it wrote as json is converted to the framework's representation, in which these blocks are objects
+//and the unset arguments are null. The state is expected to have the same attributes as the framework's schema.
+func sdkStateJsonToFramework(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) (tfsdk.State, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	typ := resp.State.Schema.Type().TerraformType(ctx)
+	state := tfsdk.State{
+		Schema: resp.State.Schema,
+	}
+
+	var sdkState map[string]interface{}
+	err := json.Unmarshal(req.RawState.JSON, &sdkState)
+	if err != nil {
+		diags.AddError("Error reading the state written by the sdk", err.Error())
+		return state, diags
+	}
+
+	converted, err := json.Marshal(sdkStateObjectToFramework(sdkState, typ.(tftypes.Object)))
+	if err != nil {
+		diags.AddError("Error converting the state written by the sdk", err.Error())
+		return state, diags
+	}
+
+	state.Raw, err = (&tfprotov6.RawState{JSON: converted}).Unmarshal(typ)
+	if err != nil {
+		diags.AddError("Error converting the state written by the sdk", err.Error())
+	}
+	return state, diags
+}
+
+func sdkStateObjectToFramework(object map[string]interface{}, typ tftypes.Object) map[string]interface{} {
+	converted := map[string]interface{}{}
+	for key, attrType := range typ.AttributeTypes {
+		converted[key] = sdkStateValueToFramework(object[key], attrType)
+	}
+	return converted
+}
+
+func sdkStateValueToFramework(value interface{}, typ tftypes.Type) interface{} {
+	switch {
+	case typ.Is(tftypes.Object{}):
+		//Blocks limited to a single element are kept even if none of their arguments are set
+		if list, isList := value.([]interface{}); isList {
+			if len(list) == 0 {
+				return nil
+			}
+			element, isObject := list[0].(map[string]interface{})
+			if !isObject {
+				return nil
+			}
+			return sdkStateObjectToFramework(element, typ.(tftypes.Object))
+		}
+
+		//Objects stored as such by the sdk, like the timeouts, are null if none of their values are set
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return nil
+		}
+		converted := sdkStateObjectToFramework(object, typ.(tftypes.Object))
+		for _, val := range converted {
+			if val != nil {
+				return converted
+			}
+		}
+		return nil
+	case typ.Is(tftypes.List{}) || typ.Is(tftypes.Set{}):
+		var elemType tftypes.Type
+		if typ.Is(tftypes.List{}) {
+			elemType = typ.(tftypes.List).ElementType
+		} else {
+			elemType = typ.(tftypes.Set).ElementType
+		}
+
+		list, _ := value.([]interface{})
+
+		//Nested blocks are empty rather than null when none are specified
+		if elemType.Is(tftypes.Object{}) {
+			converted := []interface{}{}
+			for _, element := range list {
+				object, isObject := element.(map[string]interface{})
+				if isObject {
+					converted = append(converted, sdkStateObjectToFramework(object, elemType.(tftypes.Object)))
+				}
+			}
+			return converted
+		}
+
+		if len(list) == 0 {
+			return nil
+		}
+		return list
+	case typ.Is(tftypes.Map{}):
+		object, _ := value.(map[string]interface{})
+		if len(object) == 0 {
+			return nil
+		}
+		return object
+	}
+
+	switch typedValue := value.(type) {
+	case string:
+		if typedValue == "" {
+			return nil
+		}
+	case float64:
+		if typedValue == 0 {
+			return nil
+		}
+	case bool:
+		if !typedValue {
+			return nil
+		}
+	}
+
+	return value
+}
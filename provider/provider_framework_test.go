@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func newProviderConfigValue(typ tftypes.Type, values map[string]tftypes.Value) tftypes.Value {
+	attributes := map[string]tftypes.Value{}
+	for key, attrType := range typ.(tftypes.Object).AttributeTypes {
+		value, ok := values[key]
+		if !ok {
+			value = tftypes.NewValue(attrType, nil)
+		}
+		attributes[key] = value
+	}
+
+	return tftypes.NewValue(typ, attributes)
+}
+
+func TestFrameworkProviderSettings(t *testing.T) {
+	sdkProvider := Provider()
+	sdkSchema, err := sdkProvider.GRPCProvider().GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("Failed to retrieve the schema of the provider: %s", err.Error())
+	}
+	configType := sdkSchema.Provider.Block.ValueType()
+	awsAuthType := configType.(tftypes.Object).AttributeTypes["aws_auth"].(tftypes.Set).ElementType
+
+	config := newProviderConfigValue(configType, map[string]tftypes.Value{
+		"endpoints":             tftypes.NewValue(tftypes.String, "https://localhost:9200"),
+		"insecure_skip_verify":  tftypes.NewValue(tftypes.Bool, true),
+		"retries":               tftypes.NewValue(tftypes.Number, 5),
+		"retry_on_status_codes": tftypes.NewValue(tftypes.List{ElementType: tftypes.Number}, []tftypes.Value{tftypes.NewValue(tftypes.Number, 429), tftypes.NewValue(tftypes.Number, 503)}),
+		"headers":               tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"X-Tenant": tftypes.NewValue(tftypes.String, "demo")}),
+		"aws_auth": tftypes.NewValue(configType.(tftypes.Object).AttributeTypes["aws_auth"], []tftypes.Value{
+			newProviderConfigValue(awsAuthType, map[string]tftypes.Value{
+				"region": tftypes.NewValue(tftypes.String, "us-east-1"),
+			}),
+		}),
+	})
+
+	settings, err := newFrameworkProviderSettings(config, sdkProvider.Schema)
+	if err != nil {
+		t.Fatalf("Failed to convert the configuration: %s", err.Error())
+	}
+
+	expected := schema.TestResourceDataRaw(t, sdkProvider.Schema, map[string]interface{}{
+		"endpoints":             "https://localhost:9200",
+		"insecure_skip_verify":  true,
+		"retries":               5,
+		"retry_on_status_codes": []interface{}{429, 503},
+		"headers":               map[string]interface{}{"X-Tenant": "demo"},
+		"aws_auth": []interface{}{
+			map[string]interface{}{
+				"region": "us-east-1",
+			},
+		},
+	})
+
+	for key := range sdkProvider.Schema {
+		expectedValue := expected.Get(key)
+		value := settings.Get(key)
+
+		if expectedSet, ok := expectedValue.(*schema.Set); ok {
+			set, isSet := value.(*schema.Set)
+			if !isSet {
+				t.Errorf("Expected a set for '%s', got %T", key, value)
+				continue
+			}
+			expectedValue = expectedSet.List()
+			value = set.List()
+		}
+
+		if !reflect.DeepEqual(value, expectedValue) {
+			t.Errorf("Expected %#v for '%s', got %#v", expectedValue, key, value)
+		}
+	}
+}
+
+func TestProviderArgumentsValidation(t *testing.T) {
+	ctx := context.Background()
+	sdkServer := Provider().GRPCProvider()
+	sdkSchema, err := sdkServer.GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("Failed to retrieve the schema of the provider: %s", err.Error())
+	}
+	configType := sdkSchema.Provider.Block.ValueType()
+
+	tests := []struct {
+		name    string
+		retries int
+		valid   bool
+	}{
+		{name: "no retries", retries: 0, valid: true},
+		{name: "some retries", retries: 3, valid: true},
+		{name: "negative retries", retries: -1, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newProviderConfigValue(configType, map[string]tftypes.Value{
+				"endpoints": tftypes.NewValue(tftypes.String, "https://localhost:9200"),
+				"retries":   tftypes.NewValue(tftypes.Number, tt.retries),
+			})
+
+			sdkConfig, _ := tfprotov5.NewDynamicValue(configType, config)
+			sdkResp, err := sdkServer.PrepareProviderConfig(ctx, &tfprotov5.PrepareProviderConfigRequest{Config: &sdkConfig})
+			if err != nil {
+				t.Fatalf("Failed to validate the sdk configuration: %s", err.Error())
+			}
+			if (len(sdkResp.Diagnostics) == 0) != tt.valid {
+				t.Errorf("Expected the sdk configuration to be valid: %t, got %v", tt.valid, sdkResp.Diagnostics)
+			}
+
+			frameworkConfig, _ := tfprotov6.NewDynamicValue(configType, config)
+			frameworkResp, err := providerserver.NewProtocol6(NewFrameworkProvider())().ValidateProviderConfig(ctx, &tfprotov6.ValidateProviderConfigRequest{Config: &frameworkConfig})
+			if err != nil {
+				t.Fatalf("Failed to validate the framework configuration: %s", err.Error())
+			}
+			if (len(frameworkResp.Diagnostics) == 0) != tt.valid {
+				t.Errorf("Expected the framework configuration to be valid: %t, got %v", tt.valid, frameworkResp.Diagnostics)
+			}
+
+			//Served through the mux, the framework provider leaves the diagnostics of the arguments to the sdk provider
+			muxedResp, err := NewFrameworkProviderServer()().ValidateProviderConfig(ctx, &tfprotov6.ValidateProviderConfigRequest{Config: &frameworkConfig})
+			if err != nil {
+				t.Fatalf("Failed to validate the muxed configuration: %s", err.Error())
+			}
+			if len(muxedResp.Diagnostics) > 0 {
+				t.Errorf("Expected no diagnostics from the muxed framework provider, got %v", muxedResp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestProvidersShareTheClient(t *testing.T) {
+	ctx := context.Background()
+	sdkProvider := Provider()
+	sdkSchema, err := sdkProvider.GRPCProvider().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("Failed to retrieve the schema of the provider: %s", err.Error())
+	}
+	configType := sdkSchema.Provider.Block.ValueType()
+
+	//Nothing listens on the endpoints, so the detection of the cluster fails right away and is only logged
+	configure := func(endpoints string) (OpensearchClient, OpensearchClient) {
+		config := newProviderConfigValue(configType, map[string]tftypes.Value{
+			"endpoints": tftypes.NewValue(tftypes.String, endpoints),
+			"retries":   tftypes.NewValue(tftypes.Number, 0),
+		})
+		settings, err := newFrameworkProviderSettings(config, sdkProvider.Schema)
+		if err != nil {
+			t.Fatalf("Failed to convert the configuration: %s", err.Error())
+		}
+
+		frameworkCli, err := getOpensearchClient(ctx, settings)
+		if err != nil {
+			t.Fatalf("Failed to configure the framework client: %s", err.Error())
+		}
+
+		sdkCli, err := getOpensearchClient(ctx, schema.TestResourceDataRaw(t, sdkProvider.Schema, map[string]interface{}{
+			"endpoints": endpoints,
+			"retries":   0,
+		}))
+		if err != nil {
+			t.Fatalf("Failed to configure the sdk client: %s", err.Error())
+		}
+
+		return frameworkCli, sdkCli
+	}
+
+	frameworkCli, sdkCli := configure("http://127.0.0.1:1")
+	if frameworkCli.Health == nil || frameworkCli.Health != sdkCli.Health {
+		t.Errorf("Expected the providers to share the health of the endpoints")
+	}
+
+	otherCli, _ := configure("http://127.0.0.1:2")
+	if otherCli.Health == frameworkCli.Health {
+		t.Errorf("Expected different settings to get a different client")
+	}
+}
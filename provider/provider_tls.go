@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/youmark/pkcs8"
+)
+
+//Certificates and keys can be passed either as a file path or directly as pem content
+func loadPem(value string, description string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+
+	content, err := ioutil.ReadFile(value)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to read %s file: %s", description, err.Error()))
+	}
+
+	return content, nil
+}
+
+//Parses all the certificates of a bundle, failing if any of them is invalid rather than silently ignoring it
+func loadCaCertPool(caCert string) (*x509.CertPool, error) {
+	content, err := loadPem(caCert, "root certificate")
+	if err != nil {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	certsCount := 0
+	for {
+		block, rest := pem.Decode(content)
+		if block == nil {
+			break
+		}
+		content = rest
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, certErr := x509.ParseCertificate(block.Bytes)
+		if certErr != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to parse root certificate authority: %s", certErr.Error()))
+		}
+		roots.AddCert(cert)
+		certsCount += 1
+	}
+
+	if certsCount == 0 {
+		return nil, errors.New("Failed to parse root certificate authority: no certificate found")
+	}
+
+	return roots, nil
+}
+
+//Decrypts a private key encrypted either in the pkcs8 format or in the legacy openssl format.
+//Unencrypted keys are returned as is.
+func decryptPrivateKey(keyPem []byte, password string) ([]byte, error) {
+	block, _ := pem.Decode(keyPem)
+	if block == nil {
+		return nil, errors.New("Failed to decode client key: no pem data found")
+	}
+
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		key, keyErr := pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(password))
+		if keyErr != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to decrypt client key: %s", keyErr.Error()))
+		}
+
+		der, derErr := x509.MarshalPKCS8PrivateKey(key)
+		if derErr != nil {
+			return nil, derErr
+		}
+
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+
+	//Legacy openssl encryption is insecure, but still commonly found in the wild
+	if x509.IsEncryptedPEMBlock(block) {
+		der, derErr := x509.DecryptPEMBlock(block, []byte(password))
+		if derErr != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to decrypt client key: %s", derErr.Error()))
+		}
+
+		return pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: der}), nil
+	}
+
+	return keyPem, nil
+}
+
+func loadClientCertificate(cert string, key string, keyPassword string) (*tls.Certificate, error) {
+	certPem, certErr := loadPem(cert, "client certificate")
+	if certErr != nil {
+		return nil, certErr
+	}
+
+	keyPem, keyErr := loadPem(key, "client key")
+	if keyErr != nil {
+		return nil, keyErr
+	}
+
+	if keyPassword != "" {
+		keyPem, keyErr = decryptPrivateKey(keyPem, keyPassword)
+		if keyErr != nil {
+			return nil, keyErr
+		}
+	}
+
+	certData, err := tls.X509KeyPair(certPem, keyPem)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certData, nil
+}
@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	//Embeds the IANA timezone database so that timezones can be validated on hosts that lack it
+	_ "time/tzdata"
+)
+
+type cronFieldSpec struct {
+	Name     string
+	Min      int
+	Max      int
+	Names    map[string]int
+	Specials *regexp.Regexp
+}
+
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var unixCronDayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+//Days of the week are numbered from 1 (sunday) to 7 (saturday) in the quartz format
+var quartzCronDayNames = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
+
+var unixCronFields = []cronFieldSpec{
+	{Name: "minute", Min: 0, Max: 59},
+	{Name: "hour", Min: 0, Max: 23},
+	{Name: "day of month", Min: 1, Max: 31},
+	{Name: "month", Min: 1, Max: 12, Names: cronMonthNames},
+	{Name: "day of week", Min: 0, Max: 7, Names: unixCronDayNames},
+}
+
+var quartzCronFields = []cronFieldSpec{
+	{Name: "second", Min: 0, Max: 59},
+	{Name: "minute", Min: 0, Max: 59},
+	{Name: "hour", Min: 0, Max: 23},
+	{
+		Name:     "day of month",
+		Min:      1,
+		Max:      31,
+		Specials: regexp.MustCompile(`^(\?|L(-[0-9]{1,2})?|LW|[0-9]{1,2}W)$`),
+	},
+	{Name: "month", Min: 1, Max: 12, Names: cronMonthNames},
+	{
+		Name:     "day of week",
+		Min:      1,
+		Max:      7,
+		Names:    quartzCronDayNames,
+		Specials: regexp.MustCompile(`^(\?|L|([1-7]|SUN|MON|TUE|WED|THU|FRI|SAT)(L|#[1-5]))$`),
+	},
+	{Name: "year", Min: 1970, Max: 2099},
+}
+
+func parseCronValue(value string, spec cronFieldSpec) (int, error) {
+	num, nameExists := spec.Names[value]
+	if !nameExists {
+		var convErr error
+		num, convErr = strconv.Atoi(value)
+		if convErr != nil {
+			return 0, errors.New(fmt.Sprintf("'%s' is not a valid %s", value, spec.Name))
+		}
+	}
+
+	if num < spec.Min || num > spec.Max {
+		return 0, errors.New(fmt.Sprintf("%s %d is not between %d and %d", spec.Name, num, spec.Min, spec.Max))
+	}
+
+	return num, nil
+}
+
+//Validates a single field of a cron expression, made of a comma separated list of values, ranges and steps
+func validateCronField(field string, spec cronFieldSpec) error {
+	for _, part := range strings.Split(strings.ToUpper(field), ",") {
+		if spec.Specials != nil && spec.Specials.MatchString(part) {
+			continue
+		}
+
+		rangePart := part
+		stepIdx := strings.Index(part, "/")
+		if stepIdx >= 0 {
+			step, stepErr := strconv.Atoi(part[stepIdx+1:])
+			if stepErr != nil || step < 1 {
+				return errors.New(fmt.Sprintf("'%s' is not a valid step for the %s", part[stepIdx+1:], spec.Name))
+			}
+			rangePart = part[:stepIdx]
+		}
+
+		if rangePart == "*" {
+			continue
+		}
+
+		bounds := strings.SplitN(rangePart, "-", 2)
+		start, startErr := parseCronValue(bounds[0], spec)
+		if startErr != nil {
+			return startErr
+		}
+
+		if len(bounds) == 2 {
+			end, endErr := parseCronValue(bounds[1], spec)
+			if endErr != nil {
+				return endErr
+			}
+
+			if start > end {
+				return errors.New(fmt.Sprintf("'%s' is not a valid range for the %s", rangePart, spec.Name))
+			}
+		}
+	}
+
+	return nil
+}
+
+//Accepts cron expressions in either the unix format (5 fields) or the quartz format (6 or 7 fields)
+func validateCronExpression(i interface{}, k string) ([]string, []error) {
+	expression, ok := i.(string)
+	if !ok {
+		return nil, []error{errors.New(fmt.Sprintf("expected type of %s to be string", k))}
+	}
+
+	fields := strings.Fields(expression)
+	specs := unixCronFields
+	switch len(fields) {
+	case 5:
+	case 6, 7:
+		specs = quartzCronFields[:len(fields)]
+	default:
+		return nil, []error{errors.New(fmt.Sprintf("%s: '%s' should have 5 fields (unix format) or 6 to 7 fields (quartz format), got %d", k, expression, len(fields)))}
+	}
+
+	for idx, field := range fields {
+		fieldErr := validateCronField(field, specs[idx])
+		if fieldErr != nil {
+			return nil, []error{errors.New(fmt.Sprintf("%s: '%s' is not a valid cron expression: %s", k, expression, fieldErr.Error()))}
+		}
+	}
+
+	return nil, nil
+}
+
+func validateTimezone(i interface{}, k string) ([]string, []error) {
+	timezone, ok := i.(string)
+	if !ok {
+		return nil, []error{errors.New(fmt.Sprintf("expected type of %s to be string", k))}
+	}
+
+	//The empty string and Local are accepted by the standard library but do not designate an actual timezone
+	_, locErr := time.LoadLocation(timezone)
+	if timezone == "" || timezone == "Local" || locErr != nil {
+		return nil, []error{errors.New(fmt.Sprintf("%s: '%s' is not a valid IANA timezone", k, timezone))}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestValidateCronExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		valid      bool
+	}{
+		{expression: "0 * * * *", valid: true},
+		{expression: "*/15 0-6 1,15 JAN-MAR MON-FRI", valid: true},
+		{expression: "0 0 * * 7", valid: true},
+		{expression: "0 0 12 ? * MON-FRI", valid: true},
+		{expression: "0 0 12 L * ?", valid: true},
+		{expression: "0 0 12 15W * ?", valid: true},
+		{expression: "0 0 12 ? * 6#3", valid: true},
+		{expression: "0 0 12 ? * FRIL 2030", valid: true},
+		{expression: "", valid: false},
+		{expression: "* * * *", valid: false},
+		{expression: "* * * * * * * *", valid: false},
+		{expression: "60 * * * *", valid: false},
+		{expression: "0 24 * * *", valid: false},
+		{expression: "0 0 0 * *", valid: false},
+		{expression: "0 0 * 13 *", valid: false},
+		{expression: "0 0 * * 8", valid: false},
+		{expression: "0 0 * * FOO", valid: false},
+		{expression: "*/0 * * * *", valid: false},
+		{expression: "30-10 * * * *", valid: false},
+		{expression: "0 0 * * ?", valid: false},
+		{expression: "0 0 12 ? * 0", valid: false},
+		{expression: "0 0 12 ? * MON 1969", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, errs := validateCronExpression(tt.expression, "expression")
+			if (len(errs) == 0) != tt.valid {
+				t.Errorf("Expected valid to be %t, got errors %v", tt.valid, errs)
+			}
+		})
+	}
+
+	_, errs := validateCronExpression(5, "expression")
+	if len(errs) == 0 {
+		t.Errorf("Expected an error for a value that is not a string")
+	}
+}
+
+func TestValidateTimezone(t *testing.T) {
+	tests := []struct {
+		timezone string
+		valid    bool
+	}{
+		{timezone: "UTC", valid: true},
+		{timezone: "America/Montreal", valid: true},
+		{timezone: "Europe/Paris", valid: true},
+		{timezone: "", valid: false},
+		{timezone: "Local", valid: false},
+		{timezone: "America/Nowhere", valid: false},
+		{timezone: "+05:00", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			_, errs := validateTimezone(tt.timezone, "timezone")
+			if (len(errs) == 0) != tt.valid {
+				t.Errorf("Expected valid to be %t, got errors %v", tt.valid, errs)
+			}
+		})
+	}
+}
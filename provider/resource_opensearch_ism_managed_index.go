@@ -0,0 +1,272 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceOpensearchIsmManagedIndex() *schema.Resource {
+	return &schema.Resource{
+		Description: "Ism policy managing existing indices. Ism templates only apply to indices created after the policy, this resource applies the policy to the indices that already exist.",
+		CreateContext: resourceOpensearchIsmManagedIndexCreate,
+		CustomizeDiff: resourceOpensearchIsmManagedIndexCustomizeDiff,
+		UpdateContext: resourceOpensearchIsmManagedIndexUpdate,
+		ReadContext:   resourceOpensearchIsmManagedIndexRead,
+		DeleteContext: resourceOpensearchIsmManagedIndexDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		//Operations go through all the indices matching the name, which can be numerous
+		Timeouts: &schema.ResourceTimeout{
+			Default: schema.DefaultTimeout(20 * time.Minute),
+			Read:    schema.DefaultTimeout(5 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Description: "Name of the indices to manage. Wildcards and comma separated lists are supported. Indices matching the name that are created later are added to the policy at the next apply.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"policy_id": {
+				Description: "Id of the policy managing the indices.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     false,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"state": {
+				Description: "State of the policy to move the indices to when the policy is added to them. If omitted, they stay in the default state of the policy. Ism only moves the indices once they have completed the actions of the default state, so this state takes effect eventually rather than when the resource is applied.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     false,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"indices": {
+				Description: "Indices matching the name that are managed by the policy.",
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"unmanaged_indices": {
+				Description: "Indices matching the name that are not managed by the policy, either because they are managed by another policy or by none. They are added to the policy at the next apply.",
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func resourceOpensearchIsmManagedIndexCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cli := meta.(OpensearchClient)
+	index := d.Get("index").(string)
+	policyId := d.Get("policy_id").(string)
+
+	update, err := cli.GetRequestContext(ctx).AddIsmPolicy(index, policyId)
+	if err == nil {
+		err = update.GetFailuresError()
+	}
+	if err != nil {
+		return diag.Errorf("Error adding policy '%s' to indices '%s': %s", policyId, index, err.Error())
+	}
+
+	state, stateExists := d.GetOk("state")
+	if stateExists {
+		change := IsmChangePolicyModel{
+			PolicyId: policyId,
+			State:    state.(string),
+		}
+
+		update, err = cli.GetRequestContext(ctx).ChangeIsmPolicy(index, change)
+		if err == nil {
+			err = update.GetFailuresError()
+		}
+		if err != nil {
+			return diag.Errorf("Error moving indices '%s' to state '%s': %s", index, state.(string), err.Error())
+		}
+	}
+
+	d.SetId(index)
+	return resourceOpensearchIsmManagedIndexRead(ctx, d, meta)
+}
+
+//The indices are expected to all be managed by the policy.
+//The ones managed by another policy or not managed at all are reported in the unmanaged indices, which triggers an update.
+//If none are managed anymore, the resource is considered deleted.
+func resourceOpensearchIsmManagedIndexRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cli := meta.(OpensearchClient)
+	index := d.Id()
+
+	explanations, err := cli.GetRequestContext(ctx).ExplainIsmIndices(index)
+	if IsNotFound(err) {
+		d.SetId("")
+		return diag.Diagnostics{diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Indices not found",
+			Detail:   fmt.Sprintf("Indices '%s' were not found, removing them from the state", index),
+		}}
+	}
+
+	if err != nil {
+		return diag.Errorf("Error retrieving the ism status of indices '%s': %s", index, err.Error())
+	}
+
+	managedCount := 0
+	for _, explanation := range explanations {
+		if explanation.IsManaged() {
+			managedCount += 1
+		}
+	}
+
+	//Names that match no index yet have nothing to drift from
+	if len(explanations) > 0 && managedCount == 0 {
+		d.SetId("")
+		return diag.Diagnostics{diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Indices not managed anymore",
+			Detail:   fmt.Sprintf("None of the indices '%s' are managed by an ism policy anymore, removing them from the state", index),
+		}}
+	}
+
+	//The policy id is not known yet when the resource is imported, so it is taken from the managed indices
+	policyId := d.Get("policy_id").(string)
+	if policyId == "" {
+		for _, explanation := range explanations {
+			if explanation.IsManaged() {
+				policyId = explanation.GetPolicyId()
+				break
+			}
+		}
+	}
+
+	indices := []string{}
+	unmanagedIndices := []string{}
+	for name, explanation := range explanations {
+		if explanation.IsManaged() && explanation.GetPolicyId() == policyId {
+			indices = append(indices, name)
+		} else {
+			unmanagedIndices = append(unmanagedIndices, name)
+		}
+	}
+
+	d.Set("index", index)
+	d.Set("policy_id", policyId)
+	d.Set("indices", indices)
+	d.Set("unmanaged_indices", unmanagedIndices)
+
+	return nil
+}
+
+//Indices that drifted from the policy are brought back to it by an update
+func resourceOpensearchIsmManagedIndexCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	unmanagedIndices, _ := d.Get("unmanaged_indices").(*schema.Set)
+	if d.Id() != "" && unmanagedIndices != nil && unmanagedIndices.Len() > 0 {
+		return d.SetNewComputed("unmanaged_indices")
+	}
+
+	return nil
+}
+
+//Indices managed by another policy are switched to the policy and the ones that are not managed are added to it
+func resourceOpensearchIsmManagedIndexUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cli := meta.(OpensearchClient)
+	index := d.Id()
+	policyId := d.Get("policy_id").(string)
+
+	explanations, err := cli.GetRequestContext(ctx).ExplainIsmIndices(index)
+	if err != nil {
+		return diag.Errorf("Error retrieving the ism status of indices '%s': %s", index, err.Error())
+	}
+
+	toChange := []string{}
+	toAdd := []string{}
+	for name, explanation := range explanations {
+		if !explanation.IsManaged() {
+			toAdd = append(toAdd, name)
+		} else if explanation.GetPolicyId() != policyId {
+			toChange = append(toChange, name)
+		}
+	}
+
+	change := IsmChangePolicyModel{
+		PolicyId: policyId,
+	}
+	update, err := cli.ChangeIsmPolicyOfIndices(ctx, toChange, change)
+	if err == nil {
+		err = update.GetFailuresError()
+	}
+	if err != nil {
+		return diag.Errorf("Error switching indices '%s' to policy '%s': %s", index, policyId, err.Error())
+	}
+
+	update, err = cli.AddIsmPolicyToIndices(ctx, toAdd, policyId)
+	if err == nil {
+		err = update.GetFailuresError()
+	}
+	if err != nil {
+		return diag.Errorf("Error adding policy '%s' to indices '%s': %s", policyId, index, err.Error())
+	}
+
+	//Like on creation, the indices the policy is added to are moved to the configured state
+	state, stateExists := d.GetOk("state")
+	if stateExists {
+		change := IsmChangePolicyModel{
+			PolicyId: policyId,
+			State:    state.(string),
+		}
+
+		update, err = cli.ChangeIsmPolicyOfIndices(ctx, toAdd, change)
+		if err == nil {
+			err = update.GetFailuresError()
+		}
+		if err != nil {
+			return diag.Errorf("Error moving indices '%s' to state '%s': %s", index, state.(string), err.Error())
+		}
+	}
+
+	return resourceOpensearchIsmManagedIndexRead(ctx, d, meta)
+}
+
+//Indices that were moved to another policy outside of terraform are left alone
+func resourceOpensearchIsmManagedIndexDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cli := meta.(OpensearchClient)
+	index := d.Id()
+	policyId := d.Get("policy_id").(string)
+
+	explanations, err := cli.GetRequestContext(ctx).ExplainIsmIndices(index)
+	if IsNotFound(err) {
+		return nil
+	}
+
+	if err != nil {
+		return diag.Errorf("Error retrieving the ism status of indices '%s': %s", index, err.Error())
+	}
+
+	toRemove := []string{}
+	for name, explanation := range explanations {
+		if explanation.GetPolicyId() == policyId {
+			toRemove = append(toRemove, name)
+		}
+	}
+
+	update, err := cli.RemoveIsmPolicyFromIndices(ctx, toRemove)
+	if err == nil {
+		err = update.GetFailuresError()
+	}
+	if err != nil {
+		return diag.Errorf("Error removing policy '%s' from indices '%s': %s", policyId, index, err.Error())
+	}
+
+	return nil
+}
@@ -1,263 +1,1523 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
-func resourceOpensearchIsmPolicy() *schema.Resource {
-	return &schema.Resource{
-		Description: "Opensearch ism policy. Not all the options supported by the api are supported by the provider at this time.",
-		Create: resourceOpensearchIsmPolicyCreate,
-		Update: resourceOpensearchIsmPolicyUpdate,
-		Read:   resourceOpensearchIsmPolicyRead,
-		Delete: resourceOpensearchIsmPolicyDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+type ismPolicyResource struct {
+	data *frameworkProviderData
+}
+
+type ismPolicyResourceModel struct {
+	Id                     types.String                          `tfsdk:"id"`
+	PolicyId               types.String                          `tfsdk:"policy_id"`
+	PolicyJson             types.String                          `tfsdk:"policy_json"`
+	Description            types.String                          `tfsdk:"description"`
+	IsmTemplate            []ismTemplateResourceModel            `tfsdk:"ism_template"`
+	ErrorNotification      *ismNotificationResourceModel         `tfsdk:"error_notification"`
+	DefaultState           types.String                          `tfsdk:"default_state"`
+	UpdateManagedIndices   *ismUpdateManagedIndicesResourceModel `tfsdk:"update_managed_indices"`
+	SwitchedManagedIndices types.Int64                           `tfsdk:"switched_managed_indices"`
+	States                 []ismStateResourceModel               `tfsdk:"states"`
+	Timeouts               types.Object                          `tfsdk:"timeouts"`
+}
+
+type ismTemplateResourceModel struct {
+	Priority      types.Int64 `tfsdk:"priority"`
+	IndexPatterns types.Set   `tfsdk:"index_patterns"`
+}
+
+type ismUpdateManagedIndicesResourceModel struct {
+	State   types.String `tfsdk:"state"`
+	Include types.Set    `tfsdk:"include"`
+}
+
+type ismStateResourceModel struct {
+	Name        types.String                 `tfsdk:"name"`
+	Actions     []ismActionResourceModel     `tfsdk:"actions"`
+	Transitions []ismTransitionResourceModel `tfsdk:"transitions"`
+}
+
+type ismActionResourceModel struct {
+	Timeout       types.String                  `tfsdk:"timeout"`
+	Retry         *ismRetryResourceModel        `tfsdk:"retry"`
+	Action        types.String                  `tfsdk:"action"`
+	IndexPriority types.Int64                   `tfsdk:"index_priority"`
+	ReplicaCount  types.Int64                   `tfsdk:"replica_count"`
+	Rollover      *ismRolloverResourceModel     `tfsdk:"rollover"`
+	ForceMerge    *ismForceMergeResourceModel   `tfsdk:"force_merge"`
+	Shrink        *ismShrinkResourceModel       `tfsdk:"shrink"`
+	Allocation    *ismAllocationResourceModel   `tfsdk:"allocation"`
+	Snapshot      *ismSnapshotResourceModel     `tfsdk:"snapshot"`
+	Rollup        *ismRollupResourceModel       `tfsdk:"rollup"`
+	Notification  *ismNotificationResourceModel `tfsdk:"notification"`
+	Transform     *ismTransformResourceModel    `tfsdk:"transform"`
+}
+
+type ismRetryResourceModel struct {
+	Count   types.Int64  `tfsdk:"count"`
+	Backoff types.String `tfsdk:"backoff"`
+	Delay   types.String `tfsdk:"delay"`
+}
+
+type ismRolloverResourceModel struct {
+	MinSize             types.String `tfsdk:"min_size"`
+	MinPrimaryShardSize types.String `tfsdk:"min_primary_shard_size"`
+	MinDocCount         types.Int64  `tfsdk:"min_doc_count"`
+	MinIndexAge         types.String `tfsdk:"min_index_age"`
+	CopyAlias           types.Bool   `tfsdk:"copy_alias"`
+}
+
+type ismForceMergeResourceModel struct {
+	MaxNumSegments types.Int64 `tfsdk:"max_num_segments"`
+}
+
+type ismShrinkResourceModel struct {
+	NumNewShards             types.Int64   `tfsdk:"num_new_shards"`
+	MaxShardSize             types.String  `tfsdk:"max_shard_size"`
+	PercentageOfSourceShards types.Float64 `tfsdk:"percentage_of_source_shards"`
+	TargetIndexNameSuffix    types.String  `tfsdk:"target_index_name_suffix"`
+	ForceUnsafe              types.Bool    `tfsdk:"force_unsafe"`
+}
+
+type ismAllocationResourceModel struct {
+	Require types.Map  `tfsdk:"require"`
+	Include types.Map  `tfsdk:"include"`
+	Exclude types.Map  `tfsdk:"exclude"`
+	WaitFor types.Bool `tfsdk:"wait_for"`
+}
+
+type ismSnapshotResourceModel struct {
+	Repository types.String `tfsdk:"repository"`
+	Snapshot   types.String `tfsdk:"snapshot"`
+}
+
+type ismDimensionResourceModel struct {
+	Type             types.String  `tfsdk:"type"`
+	SourceField      types.String  `tfsdk:"source_field"`
+	TargetField      types.String  `tfsdk:"target_field"`
+	FixedInterval    types.String  `tfsdk:"fixed_interval"`
+	CalendarInterval types.String  `tfsdk:"calendar_interval"`
+	Timezone         types.String  `tfsdk:"timezone"`
+	Interval         types.Float64 `tfsdk:"interval"`
+}
+
+type ismRollupMetricResourceModel struct {
+	SourceField types.String `tfsdk:"source_field"`
+	Metrics     types.Set    `tfsdk:"metrics"`
+}
+
+type ismRollupResourceModel struct {
+	Description types.String                   `tfsdk:"description"`
+	TargetIndex types.String                   `tfsdk:"target_index"`
+	PageSize    types.Int64                    `tfsdk:"page_size"`
+	Dimensions  []ismDimensionResourceModel    `tfsdk:"dimensions"`
+	Metrics     []ismRollupMetricResourceModel `tfsdk:"metrics"`
+}
+
+type ismTransformResourceModel struct {
+	Description        types.String                `tfsdk:"description"`
+	TargetIndex        types.String                `tfsdk:"target_index"`
+	PageSize           types.Int64                 `tfsdk:"page_size"`
+	DataSelectionQuery types.String                `tfsdk:"data_selection_query"`
+	Groups             []ismDimensionResourceModel `tfsdk:"groups"`
+	Aggregations       types.String                `tfsdk:"aggregations"`
+}
+
+type ismDestinationResourceModel struct {
+	Type types.String `tfsdk:"type"`
+	Url  types.String `tfsdk:"url"`
+}
+
+type ismMessageTemplateResourceModel struct {
+	Source types.String `tfsdk:"source"`
+	Lang   types.String `tfsdk:"lang"`
+}
+
+type ismNotificationResourceModel struct {
+	ChannelId       types.String                     `tfsdk:"channel_id"`
+	Destination     *ismDestinationResourceModel     `tfsdk:"destination"`
+	MessageTemplate *ismMessageTemplateResourceModel `tfsdk:"message_template"`
+}
+
+type ismCronResourceModel struct {
+	Expression types.String `tfsdk:"expression"`
+	Timezone   types.String `tfsdk:"timezone"`
+}
+
+type ismConditionsResourceModel struct {
+	MinIndexAge    types.String          `tfsdk:"min_index_age"`
+	MinRolloverAge types.String          `tfsdk:"min_rollover_age"`
+	MinDocCount    types.Int64           `tfsdk:"min_doc_count"`
+	MinSize        types.String          `tfsdk:"min_size"`
+	Cron           *ismCronResourceModel `tfsdk:"cron"`
+}
+
+type ismTransitionResourceModel struct {
+	StateName  types.String                `tfsdk:"state_name"`
+	Conditions *ismConditionsResourceModel `tfsdk:"conditions"`
+}
+
+func NewIsmPolicyResource() resource.Resource {
+	return &ismPolicyResource{}
+}
+
+func (r *ismPolicyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ism_policy"
+}
+
+func (r *ismPolicyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Opensearch ism policy. Not all the options supported by the api are supported by the typed arguments at this time, the policy_json argument can be used for the others.",
+		Version:             2,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The ID of this resource.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"policy_id": schema.StringAttribute{
+				MarkdownDescription: "Unique identifier for the policy.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
+				},
+			},
+			"policy_json": schema.StringAttribute{
+				MarkdownDescription: "Json document of the policy, as an alternative to the description, ism_template, error_notification, default_state and states arguments. It supports all the options of the api. Fields of the policy that opensearch sets to their default value are ignored when comparing the policy with this document.",
+				Optional:            true,
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					ismPolicyJsonModifier{},
+				},
+				Validators: []validator.String{
+					sdkStringValidator{validate: validation.StringIsJSON, description: "value must be a json document"},
+				},
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "Description for the policy. Required if the states are specified.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
+				},
+			},
+			"default_state": schema.StringAttribute{
+				MarkdownDescription: "Default states that indices will have. Required if the states are specified.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
+				},
+			},
+			"switched_managed_indices": schema.Int64Attribute{
+				MarkdownDescription: "Number of managed indices that the last update of the policy switched to its new version. Only set when update_managed_indices is specified.",
+				Computed:            true,
+			},
 		},
-		Schema: map[string]*schema.Schema{
-			//Missing
-			//Fields: error_notification
-			"policy_id": {
-				Description: "Unique identifier for the policy.",
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     true,
-				ValidateFunc: validation.StringIsNotEmpty,
+		Blocks: map[string]schema.Block{
+			"ism_template": schema.SetNestedBlock{
+				MarkdownDescription: "Match of the indices to apply the policy on.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"priority": schema.Int64Attribute{
+							MarkdownDescription: "Priority of the template when the indices match several templates. Defaults to 0.",
+							Optional:            true,
+							Computed:            true,
+							PlanModifiers: []planmodifier.Int64{
+								int64DefaultModifier{value: 0},
+							},
+							Validators: []validator.Int64{
+								int64AtLeastValidator(0),
+							},
+						},
+						"index_patterns": schema.SetAttribute{
+							MarkdownDescription: "Indexes to include with wildcard support.",
+							ElementType:         types.StringType,
+							Required:            true,
+						},
+					},
+				},
 			},
-			"description": {
-				Description: "Description for the policy.",
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     false,
-				ValidateFunc: validation.StringIsNotEmpty,
+			"error_notification": ismNotificationBlock("Notification to send when an index fails to go through the policy."),
+			"update_managed_indices": schema.SingleNestedBlock{
+				MarkdownDescription: "If specified, the indices managed by the policy are switched to its new version whenever it is updated. Otherwise, they keep running the version they started with.",
+				Attributes: map[string]schema.Attribute{
+					"state": schema.StringAttribute{
+						MarkdownDescription: "State to move the indices to once they have completed their current action. If omitted, the indices switch once they have completed their current state.",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+					"include": schema.SetAttribute{
+						MarkdownDescription: "States the indices must be in to be switched. If omitted, the indices are switched regardless of their state.",
+						ElementType:         types.StringType,
+						Optional:            true,
+						Validators: []validator.Set{
+							sdkSetElementsValidator{validate: validation.StringIsNotEmpty, description: "values must not be empty"},
+						},
+					},
+				},
 			},
-			"ism_template": {
-				Description: "Match of the indices to apply the policy on.",
-				Type:     schema.TypeSet,
-                Optional: true,
-                ForceNew: false,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"priority": {
-							Type:         schema.TypeInt,
-							Optional:     true,
-							ValidateFunc: validation.IntAtLeast(0),
-						},
-						"index_patterns": {
-							Description: "Indexes to include with wildcard support.",
-							Type: schema.TypeSet,
-							Required:     true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
+			"states": schema.ListNestedBlock{
+				MarkdownDescription: "States of the policy, in the order they are listed in the policy. Exactly one of states and policy_json must be specified.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "Name of the state.",
+							Required:            true,
+							Validators: []validator.String{
+								stringNotEmptyValidator{},
 							},
 						},
 					},
+					Blocks: map[string]schema.Block{
+						"actions": schema.ListNestedBlock{
+							MarkdownDescription: "Actions that should be run when an index reach the state.",
+							NestedObject:        ismActionBlockObject(),
+						},
+						"transitions": schema.ListNestedBlock{
+							MarkdownDescription: "Transition specifications for when an index should transition to another state.",
+							NestedObject:        ismTransitionBlockObject(),
+						},
+					},
 				},
 			},
-			"default_state": {
-				Description: "Default states that indices will have.",
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     false,
-				ValidateFunc: validation.StringIsNotEmpty,
+			"timeouts": frameworkTimeoutsBlock("update"),
+		},
+	}
+}
+
+//The arguments that the parameters of the actions require are optional in the schema,
+//since the framework would require them even for the actions the parameters are omitted from
+func ismActionBlockObject() schema.NestedBlockObject {
+	return schema.NestedBlockObject{
+		Attributes: map[string]schema.Attribute{
+			"timeout": schema.StringAttribute{
+				MarkdownDescription: "Time limit to perform the action",
+				Optional:            true,
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
+				},
+			},
+			"action": schema.StringAttribute{
+				MarkdownDescription: "The action to execute. Currently supports: read_only, read_write, replica_count, open, close, delete, index_priority, rollover, force_merge, shrink, allocation, snapshot, rollup, transform, notification",
+				Required:            true,
+				Validators: []validator.String{
+					stringInSliceValidator(
+						"read_only",
+						"read_write",
+						"replica_count",
+						"open",
+						"close",
+						"delete",
+						"index_priority",
+						"rollover",
+						"force_merge",
+						"shrink",
+						"allocation",
+						"snapshot",
+						"rollup",
+						"transform",
+						"notification",
+					),
+				},
 			},
-			"states": {
-				Description: "Permissions for index access the role has.",
-                Type:        schema.TypeSet,
-				Required:    true,
-                ForceNew:    false,
-                Elem: &schema.Resource{
-                    Schema: map[string]*schema.Schema{
-						"name": {
-							Description: "Name of the state.",
-							Type:         schema.TypeString,
-							Required:     true,
-							ValidateFunc: validation.StringIsNotEmpty,
-						},
-						"actions": {
-							Description: "Actions that should be run when an index reach the state.",
-							Type:        schema.TypeList,
-							Optional:    true,
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"timeout": {
-										Description: "Time limit to perform the action",
-										Type:         schema.TypeString,
-										Optional:     true,
-										ValidateFunc: validation.StringIsNotEmpty,
+			"index_priority": schema.Int64Attribute{
+				MarkdownDescription: "Priority to set for the index if the action is index_priority",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64AtLeastValidator(0),
+				},
+			},
+			"replica_count": schema.Int64Attribute{
+				MarkdownDescription: "Replicat count to set for the index if the action is replica_count",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64AtLeastValidator(0),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"retry": schema.SingleNestedBlock{
+				MarkdownDescription: "Retry policy when the action fails",
+				Attributes: map[string]schema.Attribute{
+					"count": schema.Int64Attribute{
+						MarkdownDescription: "Number of retries",
+						Optional:            true,
+						Validators: []validator.Int64{
+							requiredInBlockValidator{},
+							int64AtLeastValidator(1),
+						},
+					},
+					"backoff": schema.StringAttribute{
+						MarkdownDescription: "Backoff policy when retrying. Can be: Exponential, Constant and Linear",
+						Optional:            true,
+						Validators: []validator.String{
+							stringInSliceValidator("exponential", "linear", "constant"),
+						},
+					},
+					"delay": schema.StringAttribute{
+						MarkdownDescription: "Base time to wait between retries",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+				},
+			},
+			"rollover": schema.SingleNestedBlock{
+				MarkdownDescription: "Conditions for the rollover if the action is rollover. If omitted, the index is rolled over unconditionally. The index must have a rollover alias for the action to succeed.",
+				Attributes: map[string]schema.Attribute{
+					"min_size": schema.StringAttribute{
+						MarkdownDescription: "Minimum size of the index (not counting replication) after which it is rolled over.",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+					"min_primary_shard_size": schema.StringAttribute{
+						MarkdownDescription: "Minimum size of the largest primary shard of the index after which it is rolled over.",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+					"min_doc_count": schema.Int64Attribute{
+						MarkdownDescription: "Minimum number of documents after which the index is rolled over.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64AtLeastValidator(0),
+						},
+					},
+					"min_index_age": schema.StringAttribute{
+						MarkdownDescription: "Minimum age after which the index is rolled over.",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+					"copy_alias": schema.BoolAttribute{
+						MarkdownDescription: "Whether the aliases of the index should be copied to the new index. Defaults to false.",
+						Optional:            true,
+						Computed:            true,
+						PlanModifiers: []planmodifier.Bool{
+							boolDefaultModifier{value: false},
+						},
+					},
+				},
+			},
+			"force_merge": schema.SingleNestedBlock{
+				MarkdownDescription: "Parameters of the force merge if the action is force_merge.",
+				Attributes: map[string]schema.Attribute{
+					"max_num_segments": schema.Int64Attribute{
+						MarkdownDescription: "Number of segments to merge the shards of the index down to.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							requiredInBlockValidator{},
+							int64AtLeastValidator(1),
+						},
+					},
+				},
+			},
+			"shrink": schema.SingleNestedBlock{
+				MarkdownDescription: "Parameters of the shrink if the action is shrink. Exactly one of num_new_shards, max_shard_size and percentage_of_source_shards must be specified.",
+				Attributes: map[string]schema.Attribute{
+					"num_new_shards": schema.Int64Attribute{
+						MarkdownDescription: "Number of primary shards of the shrunken index.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64AtLeastValidator(1),
+						},
+					},
+					"max_shard_size": schema.StringAttribute{
+						MarkdownDescription: "Maximum size of the primary shards of the shrunken index, from which their number is derived.",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+					"percentage_of_source_shards": schema.Float64Attribute{
+						MarkdownDescription: "Number of primary shards of the shrunken index as a fraction (between 0 and 1) of the source index's shards.",
+						Optional:            true,
+						Validators: []validator.Float64{
+							sdkFloat64Validator{validate: validation.FloatBetween(0.0001, 0.9999), description: "value must be between 0.0001 and 0.9999"},
+						},
+					},
+					"target_index_name_suffix": schema.StringAttribute{
+						MarkdownDescription: "Suffix appended to the name of the source index to name the shrunken index. Defaults to _shrunken.",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+					"force_unsafe": schema.BoolAttribute{
+						MarkdownDescription: "If set to true, the shrink proceeds even if the index has no replicas. Defaults to false.",
+						Optional:            true,
+						Computed:            true,
+						PlanModifiers: []planmodifier.Bool{
+							boolDefaultModifier{value: false},
+						},
+					},
+				},
+			},
+			"allocation": schema.SingleNestedBlock{
+				MarkdownDescription: "Node attributes to allocate the index's shards with if the action is allocation.",
+				Attributes: map[string]schema.Attribute{
+					"require": schema.MapAttribute{
+						MarkdownDescription: "Attributes the nodes must all have to receive shards of the index.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+					"include": schema.MapAttribute{
+						MarkdownDescription: "Attributes the nodes must have at least one of to receive shards of the index.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+					"exclude": schema.MapAttribute{
+						MarkdownDescription: "Attributes the nodes must have none of to receive shards of the index.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+					"wait_for": schema.BoolAttribute{
+						MarkdownDescription: "If set to true, the action waits for the shards to be relocated before completing. Defaults to false.",
+						Optional:            true,
+						Computed:            true,
+						PlanModifiers: []planmodifier.Bool{
+							boolDefaultModifier{value: false},
+						},
+					},
+				},
+			},
+			"snapshot": schema.SingleNestedBlock{
+				MarkdownDescription: "Destination of the snapshot if the action is snapshot.",
+				Attributes: map[string]schema.Attribute{
+					"repository": schema.StringAttribute{
+						MarkdownDescription: "Name of the repository to store the snapshot in.",
+						Optional:            true,
+						Validators: []validator.String{
+							requiredInBlockValidator{},
+							stringNotEmptyValidator{},
+						},
+					},
+					"snapshot": schema.StringAttribute{
+						MarkdownDescription: "Name of the snapshot.",
+						Optional:            true,
+						Validators: []validator.String{
+							requiredInBlockValidator{},
+							stringNotEmptyValidator{},
+						},
+					},
+				},
+			},
+			"rollup": schema.SingleNestedBlock{
+				MarkdownDescription: "Rollup job to run on the index if the action is rollup.",
+				Attributes: map[string]schema.Attribute{
+					"description": schema.StringAttribute{
+						MarkdownDescription: "Description of the rollup job.",
+						Optional:            true,
+						Validators: []validator.String{
+							requiredInBlockValidator{},
+							stringNotEmptyValidator{},
+						},
+					},
+					"target_index": schema.StringAttribute{
+						MarkdownDescription: "Index to store the rolled up documents in.",
+						Optional:            true,
+						Validators: []validator.String{
+							requiredInBlockValidator{},
+							stringNotEmptyValidator{},
+						},
+					},
+					"page_size": schema.Int64Attribute{
+						MarkdownDescription: "Number of buckets processed at a time by the rollup job.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							requiredInBlockValidator{},
+							int64AtLeastValidator(1),
+						},
+					},
+				},
+				Blocks: map[string]schema.Block{
+					"dimensions": ismDimensionBlock("Fields to group the documents by, in order."),
+					"metrics": schema.ListNestedBlock{
+						MarkdownDescription: "Aggregations to compute on the fields of the documents.",
+						NestedObject: schema.NestedBlockObject{
+							Attributes: map[string]schema.Attribute{
+								"source_field": schema.StringAttribute{
+									MarkdownDescription: "Field to aggregate.",
+									Required:            true,
+									Validators: []validator.String{
+										stringNotEmptyValidator{},
 									},
-									"retry": {
-										Description: "Retry policy when the action fails",
-										Type:     schema.TypeSet,
-										Optional: true,
-										MaxItems: 1,
-										Elem: &schema.Resource{
-											Schema: map[string]*schema.Schema{
-												"count": {
-													Description:  "Number of retries",
-													Type:         schema.TypeInt,
-													Required:     true,
-													ValidateFunc: validation.IntAtLeast(1),
-												},
-												"backoff": {
-													Description: "Backoff policy when retrying. Can be: Exponential, Constant and Linear",
-													Type:         schema.TypeString,
-													Optional:     true,
-													ValidateFunc: validation.StringInSlice(
-														[]string{
-															"exponential",
-															"linear",
-															"constant",
-														}, 
-														false,
-													),
-												},
-												"delay": {
-													Description: "Base time to wait between retries",
-													Type:         schema.TypeString,
-													Optional:     true,
-													ValidateFunc: validation.StringIsNotEmpty,
-												},
-											},
+								},
+								"metrics": schema.SetAttribute{
+									MarkdownDescription: "Aggregations to compute on the field. Can be: avg, sum, max, min and value_count",
+									ElementType:         types.StringType,
+									Required:            true,
+									Validators: []validator.Set{
+										sdkSetElementsValidator{
+											validate:    validation.StringInSlice([]string{"avg", "sum", "max", "min", "value_count"}, false),
+											description: "values must be one of: avg, sum, max, min, value_count",
 										},
 									},
-									"action": {
-										//Missing
-										//Actions: allocation, snapshot, notification, rollover, shrink, force_merge
-										Description: "The action to execute. Currently supports: read_only, read_write, replica_count, open, close, delete, index_priority",
-										Type:         schema.TypeString,
-										Required:     true,
-										ValidateFunc: validation.StringInSlice(
-											[]string{
-												"read_only",
-												"read_write",
-												"replica_count",
-												"open",
-												"close",
-												"delete",
-												"index_priority",
-											}, 
-											false,
-										),
-									},
-									"index_priority": {
-										Description: "Priority to set for the index if the action is index_priority",
-										Type:     schema.TypeInt,
-										Optional: true,
-										ValidateFunc: validation.IntAtLeast(0),
-									},
-									"replica_count": {
-										Description: "Replicat count to set for the index if the action is replica_count",
-										Type:     schema.TypeInt,
-										Optional: true,
-										ValidateFunc: validation.IntAtLeast(1),
-									},
 								},
 							},
 						},
-						"transitions": {
-							Description: "Transition specifications for when an index should transition to another state.",
-							Type:        schema.TypeList,
-							Optional:    true,
-							Elem: &schema.Resource{
-								Schema: map[string]*schema.Schema{
-									"state_name": {
-										Description: "Name of the state to transition to.",
-										Type:         schema.TypeString,
-										Required:     true,
-										ValidateFunc: validation.StringIsNotEmpty,
-									},
-									"conditions": {
-										Description: "Conditions that trigger the state change.",
-										Type:        schema.TypeSet,
-										Required:    true,
-										MaxItems: 1,
-										Elem: &schema.Resource{
-											Schema: map[string]*schema.Schema{
-												//Missing
-												//fields: cron
-												"min_index_age": {
-													Description: "Minimum age at which the index will transition.",
-													Type:         schema.TypeString,
-													Optional:     true,
-													ValidateFunc: validation.StringIsNotEmpty,
-												},
-												"min_doc_count": {
-													Description: "Minimum number of documents after which the index will transition.",
-													Type:         schema.TypeInt,
-													Optional:     true,
-													ValidateFunc: validation.IntAtLeast(1),
-												},
-												"min_size": {
-													Description: "Minimum size (not counting replication) after which the index will transition.",
-													Type:         schema.TypeString,
-													Optional:     true,
-													ValidateFunc: validation.StringIsNotEmpty,
-												},
-											},
-										},
-									},
+					},
+				},
+			},
+			"notification": ismNotificationBlock("Notification to send if the action is notification."),
+			"transform": schema.SingleNestedBlock{
+				MarkdownDescription: "Transform job to run on the index if the action is transform.",
+				Attributes: map[string]schema.Attribute{
+					"description": schema.StringAttribute{
+						MarkdownDescription: "Description of the transform job.",
+						Optional:            true,
+						Validators: []validator.String{
+							requiredInBlockValidator{},
+							stringNotEmptyValidator{},
+						},
+					},
+					"target_index": schema.StringAttribute{
+						MarkdownDescription: "Index to store the transformed documents in.",
+						Optional:            true,
+						Validators: []validator.String{
+							requiredInBlockValidator{},
+							stringNotEmptyValidator{},
+						},
+					},
+					"page_size": schema.Int64Attribute{
+						MarkdownDescription: "Number of buckets processed at a time by the transform job.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							requiredInBlockValidator{},
+							int64AtLeastValidator(1),
+						},
+					},
+					"data_selection_query": schema.StringAttribute{
+						MarkdownDescription: "Query in json format to select the documents to transform. Defaults to all the documents. Changes made to this field outside of terraform are not detected.",
+						Optional:            true,
+						Validators: []validator.String{
+							sdkStringValidator{validate: validation.StringIsJSON, description: "value must be a json document"},
+						},
+					},
+					"aggregations": schema.StringAttribute{
+						MarkdownDescription: "Aggregations in json format to compute on each group. Changes made to this field outside of terraform are not detected.",
+						Optional:            true,
+						Validators: []validator.String{
+							sdkStringValidator{validate: validation.StringIsJSON, description: "value must be a json document"},
+						},
+					},
+				},
+				Blocks: map[string]schema.Block{
+					"groups": ismDimensionBlock("Fields to group the documents by, in order."),
+				},
+			},
+		},
+	}
+}
+
+func ismTransitionBlockObject() schema.NestedBlockObject {
+	return schema.NestedBlockObject{
+		Attributes: map[string]schema.Attribute{
+			"state_name": schema.StringAttribute{
+				MarkdownDescription: "Name of the state to transition to.",
+				Required:            true,
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"conditions": schema.SingleNestedBlock{
+				MarkdownDescription: "Conditions that trigger the state change. If omitted, the index transitions as soon as the actions of the state are completed.",
+				Attributes: map[string]schema.Attribute{
+					"min_index_age": schema.StringAttribute{
+						MarkdownDescription: "Minimum age at which the index will transition.",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+					"min_rollover_age": schema.StringAttribute{
+						MarkdownDescription: "Minimum time elapsed since the index was rolled over after which the index will transition.",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+					"min_doc_count": schema.Int64Attribute{
+						MarkdownDescription: "Minimum number of documents after which the index will transition.",
+						Optional:            true,
+						Validators: []validator.Int64{
+							int64AtLeastValidator(0),
+						},
+					},
+					"min_size": schema.StringAttribute{
+						MarkdownDescription: "Minimum size (not counting replication) after which the index will transition.",
+						Optional:            true,
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+				},
+				Blocks: map[string]schema.Block{
+					"cron": schema.SingleNestedBlock{
+						MarkdownDescription: "Schedule at which the index will transition.",
+						Attributes: map[string]schema.Attribute{
+							"expression": schema.StringAttribute{
+								MarkdownDescription: "Cron expression of the schedule, in either the unix (5 fields) or the quartz (6 or 7 fields, starting with the seconds) format.",
+								Optional:            true,
+								Validators: []validator.String{
+									requiredInBlockValidator{},
+									sdkStringValidator{validate: validateCronExpression, description: "value must be a cron expression"},
+								},
+							},
+							"timezone": schema.StringAttribute{
+								MarkdownDescription: "IANA timezone the cron expression is evaluated in (ex: America/Montreal).",
+								Optional:            true,
+								Validators: []validator.String{
+									requiredInBlockValidator{},
+									sdkStringValidator{validate: validateTimezone, description: "value must be an IANA timezone"},
 								},
 							},
 						},
-                    },
-                },
+					},
+				},
+			},
+		},
+	}
+}
+
+//Notification shared by the error_notification of the policy and the notification action
+func ismNotificationBlock(description string) schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: description,
+		Attributes: map[string]schema.Attribute{
+			"channel_id": schema.StringAttribute{
+				MarkdownDescription: "Id of the notifications plugin's channel to send the notification to. Exactly one of channel_id and destination must be specified.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"destination": schema.SingleNestedBlock{
+				MarkdownDescription: "Legacy destination to send the notification to. Exactly one of channel_id and destination must be specified.",
+				Attributes: map[string]schema.Attribute{
+					"type": schema.StringAttribute{
+						MarkdownDescription: "Type of the destination. Can be: slack, chime and custom_webhook",
+						Optional:            true,
+						Validators: []validator.String{
+							requiredInBlockValidator{},
+							stringInSliceValidator("slack", "chime", "custom_webhook"),
+						},
+					},
+					"url": schema.StringAttribute{
+						MarkdownDescription: "Url of the webhook to send the notification to.",
+						Optional:            true,
+						Validators: []validator.String{
+							requiredInBlockValidator{},
+							sdkStringValidator{validate: validation.IsURLWithHTTPorHTTPS, description: "value must be an http or https url"},
+						},
+					},
+				},
+			},
+			"message_template": schema.SingleNestedBlock{
+				MarkdownDescription: "Template of the message to send.",
+				Validators: []validator.Object{
+					requiredBlockValidator{},
+				},
+				Attributes: map[string]schema.Attribute{
+					"source": schema.StringAttribute{
+						MarkdownDescription: "Source of the template. Variables of the index's context (ex: {{ctx.index}}) can be referenced.",
+						Optional:            true,
+						Validators: []validator.String{
+							requiredInBlockValidator{},
+							stringNotEmptyValidator{},
+						},
+					},
+					"lang": schema.StringAttribute{
+						MarkdownDescription: "Language of the template. Defaults to mustache.",
+						Optional:            true,
+						Computed:            true,
+						PlanModifiers: []planmodifier.String{
+							stringDefaultModifier{value: "mustache"},
+						},
+						Validators: []validator.String{
+							stringNotEmptyValidator{},
+						},
+					},
+				},
 			},
 		},
 	}
 }
 
-func resourceOpensearchIsmPolicyRead(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	policyId := d.Id()
+func validateIsmNotification(notification *IsmNotificationModel) error {
+	if (notification.Channel == nil) == (notification.Destination == nil) {
+		return errors.New("exactly one of channel_id and destination must be specified for the notification")
+	}
 
-	policy, err := cli.GetRequestContext().GetIsmPolicy(policyId)
-	if err != nil {
-		return errors.New(fmt.Sprintf("Error retrieving existing policy '%s': %s", policyId, err.Error()))
+	return nil
+}
+
+//Grouping of documents shared by the dimensions of rollups and the groups of transforms
+func ismDimensionBlock(description string) schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: description,
+		Validators: []validator.List{
+			listSizeAtLeastValidator{min: 1},
+		},
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"type": schema.StringAttribute{
+					MarkdownDescription: "Type of grouping. Can be: date_histogram, terms and histogram",
+					Required:            true,
+					Validators: []validator.String{
+						stringInSliceValidator("date_histogram", "terms", "histogram"),
+					},
+				},
+				"source_field": schema.StringAttribute{
+					MarkdownDescription: "Field of the documents to group by.",
+					Required:            true,
+					Validators: []validator.String{
+						stringNotEmptyValidator{},
+					},
+				},
+				"target_field": schema.StringAttribute{
+					MarkdownDescription: "Field to store the group's value in. Defaults to the source field.",
+					Optional:            true,
+					Validators: []validator.String{
+						stringNotEmptyValidator{},
+					},
+				},
+				"fixed_interval": schema.StringAttribute{
+					MarkdownDescription: "Fixed duration of the buckets if the type is date_histogram. Exactly one of fixed_interval and calendar_interval must be specified for this type.",
+					Optional:            true,
+					Validators: []validator.String{
+						stringNotEmptyValidator{},
+					},
+				},
+				"calendar_interval": schema.StringAttribute{
+					MarkdownDescription: "Calendar unit of the buckets if the type is date_histogram. Exactly one of fixed_interval and calendar_interval must be specified for this type.",
+					Optional:            true,
+					Validators: []validator.String{
+						stringNotEmptyValidator{},
+					},
+				},
+				"timezone": schema.StringAttribute{
+					MarkdownDescription: "Timezone of the buckets if the type is date_histogram. Defaults to UTC.",
+					Optional:            true,
+					Validators: []validator.String{
+						stringNotEmptyValidator{},
+					},
+				},
+				"interval": schema.Float64Attribute{
+					MarkdownDescription: "Size of the buckets if the type is histogram.",
+					Optional:            true,
+					Validators: []validator.Float64{
+						sdkFloat64Validator{validate: validation.FloatAtLeast(0.0001), description: "value must be at least 0.0001"},
+					},
+				},
+			},
+		},
 	}
+}
 
-	writeIsmPolicyModelToSchema(d, policy)
+func validateIsmDimensions(dimensions []IsmDimensionModel) error {
+	for idx, dimension := range dimensions {
+		if dimension.DateHistogram != nil {
+			hasFixedInterval := dimension.DateHistogram.FixedInterval != ""
+			hasCalendarInterval := dimension.DateHistogram.CalendarInterval != ""
+			if hasFixedInterval == hasCalendarInterval {
+				return errors.New(fmt.Sprintf("grouping %d: exactly one of fixed_interval and calendar_interval must be specified for the date_histogram type", idx + 1))
+			}
+		}
+
+		if dimension.Histogram != nil && dimension.Histogram.Interval <= 0 {
+			return errors.New(fmt.Sprintf("grouping %d: interval must be specified for the histogram type", idx + 1))
+		}
+	}
 
 	return nil
 }
 
-func resourceOpensearchIsmPolicyCreate(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	policy := ismPolicySchemaToModel(d)
+//Arguments that only apply to the action of the same name
+var ismActionParameters = []string{
+	"index_priority",
+	"replica_count",
+	"rollover",
+	"force_merge",
+	"shrink",
+	"allocation",
+	"snapshot",
+	"rollup",
+	"transform",
+	"notification",
+}
+
+//Actions that cannot be performed without their arguments
+var ismActionsRequiringParameters = []string{
+	"index_priority",
+	"replica_count",
+	"force_merge",
+	"shrink",
+	"snapshot",
+	"rollup",
+	"transform",
+	"notification",
+}
+
+//Unset arguments are null, so that zero values can be told apart from them
+func isIsmActionParameterSet(action ismActionResourceModel, parameter string) bool {
+	switch parameter {
+	case "index_priority":
+		return !action.IndexPriority.IsNull()
+	case "replica_count":
+		return !action.ReplicaCount.IsNull()
+	case "rollover":
+		return action.Rollover != nil
+	case "force_merge":
+		return action.ForceMerge != nil
+	case "shrink":
+		return action.Shrink != nil
+	case "allocation":
+		return action.Allocation != nil
+	case "snapshot":
+		return action.Snapshot != nil
+	case "rollup":
+		return action.Rollup != nil
+	case "transform":
+		return action.Transform != nil
+	case "notification":
+		return action.Notification != nil
+	}
 
-	err := cli.GetRequestContext().UpsertIsmPolicy(policy)
+	return false
+}
+
+func validateIsmActionParameters(action ismActionResourceModel) error {
+	actionName := action.Action.ValueString()
+	for _, parameter := range ismActionParameters {
+		if parameter != actionName && isIsmActionParameterSet(action, parameter) {
+			return errors.New(fmt.Sprintf("%s can only be specified for the %s action, not the %s action", parameter, parameter, actionName))
+		}
+	}
+
+	for _, parameter := range ismActionsRequiringParameters {
+		if parameter == actionName && !isIsmActionParameterSet(action, parameter) {
+			return errors.New(fmt.Sprintf("%s must be specified for the %s action", parameter, actionName))
+		}
+	}
+
+	return nil
+}
+
+//Unreachable states are valid but likely a mistake, so they are reported as warnings
+func validateIsmPolicyGraph(policy *IsmPolicyModel, unknowns IsmPolicyGraphUnknowns, attrPath path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	warnings, err := policy.ValidatePartialGraph(unknowns)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error creating policy '%s': %s", policy.PolicyId, err.Error()))
+		diags.AddAttributeError(attrPath, "Invalid policy", err.Error())
+		return diags
+	}
+
+	for _, warning := range warnings {
+		diags.AddAttributeWarning(attrPath, "Unreachable state", warning)
 	}
 
-	d.SetId(policy.PolicyId)
-	return resourceOpensearchIsmPolicyRead(d, meta)
+	return diags
 }
 
-func resourceOpensearchIsmPolicyUpdate(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	policy := ismPolicySchemaToModel(d)
+func isFrameworkValueFullyKnown(ctx context.Context, value attr.Value) bool {
+	tfValue, err := value.ToTerraformValue(ctx)
+	return err == nil && tfValue.IsFullyKnown()
+}
+
+func (r *ismPolicyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var policyJson types.String
+	var description types.String
+	var defaultState types.String
+	var ismTemplate types.Set
+	var errorNotification types.Object
+	var states types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("policy_json"), &policyJson)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("description"), &description)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("default_state"), &defaultState)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("ism_template"), &ismTemplate)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("error_notification"), &errorNotification)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("states"), &states)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	hasPolicyJson := !policyJson.IsNull()
+	hasStates := states.IsUnknown() || len(states.Elements()) > 0
+	if hasPolicyJson == hasStates {
+		resp.Diagnostics.AddError("Invalid policy", "Exactly one of policy_json and states must be specified")
+		return
+	}
+
+	if hasPolicyJson {
+		conflicts := map[string]bool{
+			"description":        !description.IsNull(),
+			"ism_template":       ismTemplate.IsUnknown() || len(ismTemplate.Elements()) > 0,
+			"error_notification": !errorNotification.IsNull(),
+			"default_state":      !defaultState.IsNull(),
+		}
+		for _, key := range []string{"description", "ism_template", "error_notification", "default_state"} {
+			if conflicts[key] {
+				resp.Diagnostics.AddAttributeError(path.Root(key), "Conflicting arguments", fmt.Sprintf("%s cannot be specified with policy_json", key))
+			}
+		}
+	} else {
+		if description.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("description"), "Missing argument", "description must be specified with the states")
+		}
+		if defaultState.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("default_state"), "Missing argument", "default_state must be specified with the states")
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	//Documents that do not fit the provider's model are left for the api to validate
+	if hasPolicyJson {
+		if policyJson.IsUnknown() {
+			return
+		}
+
+		normalized, normErr := normalizeIsmPolicyJson(policyJson.ValueString())
+		if normErr != nil {
+			return
+		}
+
+		policy := IsmPolicyModel{}
+		uErr := json.Unmarshal([]byte(normalized), &policy)
+		if uErr != nil {
+			return
+		}
+
+		resp.Diagnostics.Append(validateIsmPolicyGraph(&policy, IsmPolicyGraphUnknowns{}, path.Root("policy_json"))...)
+		return
+	}
+
+	//Values interpolated from other resources are not known yet, so only the known parts of the policy are validated
+	if isFrameworkValueFullyKnown(ctx, errorNotification) && !errorNotification.IsNull() {
+		var notification *ismNotificationResourceModel
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("error_notification"), &notification)...)
+		if notification != nil {
+			notificationErr := validateIsmNotification(ismNotificationResourceModelToModel(notification))
+			if notificationErr != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("error_notification"), "Invalid notification", fmt.Sprintf("Error notification: %s", notificationErr.Error()))
+			}
+		}
+	}
+
+	if states.IsUnknown() {
+		return
+	}
+
+	graph := IsmPolicyModel{DefaultState: defaultState.ValueString(), States: []IsmPolicyStateModel{}}
+	unknowns := IsmPolicyGraphUnknowns{DefaultState: defaultState.IsUnknown()}
+	for stateIdx := range states.Elements() {
+		statePath := path.Root("states").AtListIndex(stateIdx)
+
+		var name types.String
+		var actions types.List
+		var transitions types.List
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, statePath.AtName("name"), &name)...)
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, statePath.AtName("actions"), &actions)...)
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, statePath.AtName("transitions"), &transitions)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		stateLabel := fmt.Sprintf("'%s'", name.ValueString())
+		if name.IsUnknown() {
+			stateLabel = fmt.Sprintf("%d", stateIdx + 1)
+		}
+
+		state := IsmPolicyStateModel{Name: name.ValueString(), Actions: []IsmPsActionModel{}, Transitions: []IsmPsTransitionModel{}}
+		for idx := range actions.Elements() {
+			actionPath := statePath.AtName("actions").AtListIndex(idx)
+
+			var actionValue types.Object
+			var actionName types.String
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, actionPath, &actionValue)...)
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, actionPath.AtName("action"), &actionName)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			graphAction := IsmPsActionModel{}
+			if actionName.ValueString() == "delete" {
+				graphAction.Delete = &EmptyModel{}
+			}
+			state.Actions = append(state.Actions, graphAction)
+
+			if !isFrameworkValueFullyKnown(ctx, actionValue) {
+				continue
+			}
+
+			action := ismActionResourceModel{}
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, actionPath, &action)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+
+			paramErr := validateIsmActionParameters(action)
+			if paramErr != nil {
+				resp.Diagnostics.AddAttributeError(actionPath, "Invalid action", fmt.Sprintf("Action %d of state %s: %s", idx + 1, stateLabel, paramErr.Error()))
+				continue
+			}
+
+			model, diags := ismActionResourceModelToModel(ctx, action)
+			resp.Diagnostics.Append(diags...)
+
+			if model.Shrink != nil && model.Shrink.GetSizingOptionsCount() != 1 {
+				resp.Diagnostics.AddAttributeError(actionPath, "Invalid action", fmt.Sprintf("Action %d of state %s: exactly one of num_new_shards, max_shard_size and percentage_of_source_shards must be specified for the shrink action", idx + 1, stateLabel))
+			}
+
+			if model.Rollup != nil {
+				dimErr := validateIsmDimensions(model.Rollup.IsmRollup.Dimensions)
+				if dimErr != nil {
+					resp.Diagnostics.AddAttributeError(actionPath, "Invalid action", fmt.Sprintf("Action %d of state %s, rollup %s", idx + 1, stateLabel, dimErr.Error()))
+				}
+			}
+
+			if model.Notification != nil {
+				notificationErr := validateIsmNotification(model.Notification)
+				if notificationErr != nil {
+					resp.Diagnostics.AddAttributeError(actionPath, "Invalid action", fmt.Sprintf("Action %d of state %s: %s", idx + 1, stateLabel, notificationErr.Error()))
+				}
+			}
+
+			if model.Transform != nil {
+				dimErr := validateIsmDimensions(model.Transform.IsmTransform.Groups)
+				if dimErr != nil {
+					resp.Diagnostics.AddAttributeError(actionPath, "Invalid action", fmt.Sprintf("Action %d of state %s, transform %s", idx + 1, stateLabel, dimErr.Error()))
+				}
+			}
+		}
+
+		if transitions.IsUnknown() {
+			unknowns.Transitions = true
+		}
+		for idx := range transitions.Elements() {
+			var target types.String
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, statePath.AtName("transitions").AtListIndex(idx).AtName("state_name"), &target)...)
+			if target.IsUnknown() {
+				unknowns.Transitions = true
+				continue
+			}
+			state.Transitions = append(state.Transitions, IsmPsTransitionModel{StateName: target.ValueString()})
+		}
+
+		//The actions of a state whose name is not known yet can still be checked, but not its place in the graph
+		if name.IsUnknown() {
+			unknowns.StateNames = true
+			continue
+		}
+		graph.States = append(graph.States, state)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(validateIsmPolicyGraph(&graph, unknowns, path.Root("states"))...)
+}
+
+//Opensearch returns the policy with its default values filled in, so documents that normalize to the same policy are equivalent
+type ismPolicyJsonModifier struct{}
+
+func (m ismPolicyJsonModifier) Description(ctx context.Context) string {
+	return "keeps the document of the state if the configured one is equivalent to it"
+}
+
+func (m ismPolicyJsonModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+//The document is computed only so that an equivalent document from the state can be kept, it is never set if omitted
+func (m ismPolicyJsonModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.ConfigValue.IsNull() {
+		resp.PlanValue = types.StringNull()
+		return
+	}
+
+	if req.ConfigValue.IsUnknown() || req.StateValue.IsNull() || req.StateValue.IsUnknown() {
+		return
+	}
+
+	normalizedState, stateErr := normalizeIsmPolicyJson(req.StateValue.ValueString())
+	normalizedConfig, configErr := normalizeIsmPolicyJson(req.ConfigValue.ValueString())
+	if stateErr == nil && configErr == nil && normalizedState == normalizedConfig {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+func (r *ismPolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	//A new policy does not manage any index yet
+	if req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("switched_managed_indices"), types.Int64Value(0))...)
+		return
+	}
+
+	var updateManagedIndices types.Object
+	var switched types.Int64
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("update_managed_indices"), &updateManagedIndices)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("switched_managed_indices"), &switched)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !updateManagedIndices.IsNull() && hasIsmPolicyChanges(req.Plan.Raw, req.State.Raw) {
+		switched = types.Int64Unknown()
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("switched_managed_indices"), switched)...)
+}
+
+//Changes to the arguments that describe the policy produce a new version of it
+func hasIsmPolicyChanges(plan tftypes.Value, state tftypes.Value) bool {
+	planAttributes := map[string]tftypes.Value{}
+	stateAttributes := map[string]tftypes.Value{}
+	if plan.As(&planAttributes) != nil || state.As(&stateAttributes) != nil {
+		return true
+	}
+
+	for key, value := range planAttributes {
+		switch key {
+		case "id", "timeouts", "update_managed_indices", "switched_managed_indices":
+			continue
+		}
+
+		if !value.Equal(stateAttributes[key]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *ismPolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data, diags := getFrameworkProviderData(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.data = data
+}
+
+//Versions 0 and 1 of the state were written by the sdk, which stored the blocks limited to a single element as lists.
+//Before version 1, the states were a set and their order was the one of their hashes. It is replaced by the one of the policy in opensearch at the next refresh.
+func (r *ismPolicyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			StateUpgrader: upgradeIsmPolicySdkState,
+		},
+		1: {
+			StateUpgrader: upgradeIsmPolicySdkState,
+		},
+	}
+}
+
+func upgradeIsmPolicySdkState(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	sdkState, diags := sdkStateJsonToFramework(ctx, req, resp)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := ismPolicyResourceModel{}
+	resp.Diagnostics.Append(sdkState.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	//The sdk stored zero values the same way as unset arguments and they were converted to null. Its minimums
+	//made zero invalid for the other numbers, so only the arguments that defaulted to zero or that the action
+	//requires are restored, which matches both the configurations that set them and the ones that omit them
+	for idx, _ := range state.IsmTemplate {
+		if state.IsmTemplate[idx].Priority.IsNull() {
+			state.IsmTemplate[idx].Priority = types.Int64Value(0)
+		}
+	}
+
+	for stateIdx, _ := range state.States {
+		for idx, _ := range state.States[stateIdx].Actions {
+			action := &state.States[stateIdx].Actions[idx]
+			if action.Action.ValueString() == "index_priority" && action.IndexPriority.IsNull() {
+				action.IndexPriority = types.Int64Value(0)
+			}
+			if action.Action.ValueString() == "replica_count" && action.ReplicaCount.IsNull() {
+				action.ReplicaCount = types.Int64Value(0)
+			}
+			if action.Rollover != nil && action.Rollover.CopyAlias.IsNull() {
+				action.Rollover.CopyAlias = types.BoolValue(false)
+			}
+			if action.Shrink != nil && action.Shrink.ForceUnsafe.IsNull() {
+				action.Shrink.ForceUnsafe = types.BoolValue(false)
+			}
+			if action.Allocation != nil && action.Allocation.WaitFor.IsNull() {
+				action.Allocation.WaitFor = types.BoolValue(false)
+			}
+		}
+	}
+
+	if state.SwitchedManagedIndices.IsNull() {
+		state.SwitchedManagedIndices = types.Int64Value(0)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *ismPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+//The policy is managed either from its json document or from the typed arguments, depending on which one is in the state
+func (r *ismPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	state := ismPolicyResourceModel{}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkTimeout(ctx, state.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	//The policy id is only known from the resource id when the policy is imported
+	policyId := state.Id.ValueString()
+	state.PolicyId = types.StringValue(policyId)
+
+	if !state.PolicyJson.IsNull() {
+		policyJson, err := cli.GetRequestContext(ctx).GetIsmPolicyJson(policyId)
+		if IsNotFound(err) {
+			resp.Diagnostics.AddWarning("Policy not found", fmt.Sprintf("Policy '%s' was not found, removing it from the state", policyId))
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		if err != nil {
+			resp.Diagnostics.AddError("Error retrieving policy", fmt.Sprintf("Error retrieving existing policy '%s': %s", policyId, err.Error()))
+			return
+		}
+
+		jsonErr := writeIsmPolicyJsonToResourceModel(&state, policyJson)
+		if jsonErr != nil {
+			resp.Diagnostics.AddError("Error parsing policy", fmt.Sprintf("Error parsing existing policy '%s': %s", policyId, jsonErr.Error()))
+			return
+		}
+
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+
+	policy, err := cli.GetRequestContext(ctx).GetIsmPolicy(policyId)
+	if IsNotFound(err) {
+		resp.Diagnostics.AddWarning("Policy not found", fmt.Sprintf("Policy '%s' was not found, removing it from the state", policyId))
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
-	err := cli.GetRequestContext().UpsertIsmPolicy(policy)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error updating existing policy '%s': %s", policy.PolicyId, err.Error()))
+		resp.Diagnostics.AddError("Error retrieving policy", fmt.Sprintf("Error retrieving existing policy '%s': %s", policyId, err.Error()))
+		return
 	}
 
-	return resourceOpensearchIsmPolicyRead(d, meta)
+	resp.Diagnostics.Append(writeIsmPolicyModelToResourceModel(ctx, policy, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-func resourceOpensearchIsmPolicyDelete(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	policyId := d.Id()
+func upsertIsmPolicyFromResourceModel(ctx context.Context, cli OpensearchClient, m ismPolicyResourceModel) error {
+	if !m.PolicyJson.IsNull() {
+		normalized, normErr := normalizeIsmPolicyJson(m.PolicyJson.ValueString())
+		if normErr != nil {
+			return normErr
+		}
+
+		return cli.GetRequestContext(ctx).UpsertIsmPolicyJson(m.PolicyId.ValueString(), normalized)
+	}
+
+	policy, diags := ismPolicyResourceModelToModel(ctx, m)
+	if diags.HasError() {
+		return errors.New(diags[0].Detail())
+	}
+
+	return cli.GetRequestContext(ctx).UpsertIsmPolicy(policy)
+}
 
-	err := cli.GetRequestContext().DeleteIsmPolicy(policyId)
+func (r *ismPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	plan := ismPolicyResourceModel{}
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkTimeout(ctx, plan.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := upsertIsmPolicyFromResourceModel(ctx, cli, plan)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error deleting existing policy '%s': %s", policyId, err.Error()))
+		resp.Diagnostics.AddError("Error creating policy", fmt.Sprintf("Error creating policy '%s': %s", plan.PolicyId.ValueString(), err.Error()))
+		return
 	}
 
-	return nil
-}
\ No newline at end of file
+	plan.Id = plan.PolicyId
+	plan.SwitchedManagedIndices = types.Int64Value(0)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+//Updates switch the managed indices to the new version of the policy when update_managed_indices is set
+func (r *ismPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	plan := ismPolicyResourceModel{}
+	state := ismPolicyResourceModel{}
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkOperationTimeout(ctx, plan.Timeouts, "update", 20*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := upsertIsmPolicyFromResourceModel(ctx, cli, plan)
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating policy", fmt.Sprintf("Error updating existing policy '%s': %s", plan.PolicyId.ValueString(), err.Error()))
+		return
+	}
+
+	//Changing only how the managed indices are updated does not produce a new version of the policy to switch to
+	if plan.UpdateManagedIndices != nil && hasIsmPolicyChanges(req.Plan.Raw, req.State.Raw) {
+		change, diags := ismChangePolicyResourceModelToModel(ctx, plan.PolicyId.ValueString(), *plan.UpdateManagedIndices)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		switched, diags := updateIsmPolicyManagedIndices(ctx, cli, change)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		plan.SwitchedManagedIndices = types.Int64Value(switched)
+	} else if plan.SwitchedManagedIndices.IsUnknown() {
+		plan.SwitchedManagedIndices = state.SwitchedManagedIndices
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+//Indices that fail to switch keep running the previous version of the policy, which is reported as a warning since the policy itself was updated
+func updateIsmPolicyManagedIndices(ctx context.Context, cli OpensearchClient, change IsmChangePolicyModel) (int64, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	indices, err := cli.GetRequestContext(ctx).GetIsmPolicyManagedIndices(change.PolicyId)
+	if err != nil {
+		diags.AddError("Error switching managed indices", fmt.Sprintf("Policy '%s' was updated, but its managed indices could not be retrieved to switch them to the new version: %s", change.PolicyId, err.Error()))
+		return 0, diags
+	}
+
+	if len(indices) == 0 {
+		return 0, diags
+	}
+
+	update, err := cli.ChangeIsmPolicyOfIndices(ctx, indices, change)
+	if err != nil {
+		diags.AddError("Error switching managed indices", fmt.Sprintf("Policy '%s' was updated, but its managed indices were not switched to the new version: %s", change.PolicyId, err.Error()))
+		return 0, diags
+	}
+
+	tflog.Info(ctx, fmt.Sprintf("Switched %d indices managed by policy '%s' to its new version", update.UpdatedIndices, change.PolicyId))
+
+	failuresErr := update.GetFailuresError()
+	if failuresErr != nil {
+		diags.AddWarning(
+			fmt.Sprintf("Not all the indices managed by policy '%s' were switched to its new version", change.PolicyId),
+			fmt.Sprintf("%d indices were switched, %s. They keep running the previous version of the policy.", update.UpdatedIndices, failuresErr.Error()),
+		)
+	}
+
+	return update.UpdatedIndices, diags
+}
+
+func (r *ismPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	state := ismPolicyResourceModel{}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkTimeout(ctx, state.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	policyId := state.Id.ValueString()
+	err := cli.GetRequestContext(ctx).DeleteIsmPolicy(policyId)
+	if err != nil && !IsNotFound(err) {
+		resp.Diagnostics.AddError("Error deleting policy", fmt.Sprintf("Error deleting existing policy '%s': %s", policyId, err.Error()))
+	}
+}
@@ -0,0 +1,296 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+//Strings with this value are replaced by unknown values, as if they were interpolated from other resources
+const unknownConfigValue = "(known after apply)"
+
+func TestIsmPolicyValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	ismPolicy := NewIsmPolicyResource().(*ismPolicyResource)
+
+	schemaResp := resource.SchemaResponse{}
+	ismPolicy.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	configType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	tests := []struct {
+		name             string
+		config           string
+		expectedErr      string
+		expectedWarnings []string
+	}{
+		{
+			name: "index priority of zero",
+			config: `{"policy_id": "logs", "description": "logs", "default_state": "hot", "states": [
+				{"name": "hot", "actions": [{"action": "index_priority", "index_priority": 0}]}
+			]}`,
+		},
+		{
+			name: "missing index priority",
+			config: `{"policy_id": "logs", "description": "logs", "default_state": "hot", "states": [
+				{"name": "hot", "actions": [{"action": "index_priority"}]}
+			]}`,
+			expectedErr: "index_priority must be specified for the index_priority action",
+		},
+		{
+			name: "invalid action next to an unknown value",
+			config: `{"policy_id": "logs", "description": "logs", "default_state": "hot", "states": [
+				{"name": "hot", "actions": [
+					{"action": "notification", "notification": {"channel_id": "(known after apply)", "message_template": {"source": "rolled over"}}},
+					{"action": "delete", "replica_count": 2}
+				]}
+			]}`,
+			expectedErr: "replica_count can only be specified for the replica_count action, not the delete action",
+		},
+		{
+			name: "action with an unknown value",
+			config: `{"policy_id": "logs", "description": "logs", "default_state": "hot", "states": [
+				{"name": "hot", "actions": [{"action": "shrink", "shrink": {"max_shard_size": "(known after apply)"}}]}
+			]}`,
+		},
+		{
+			name: "delete before other actions with an unknown value",
+			config: `{"policy_id": "logs", "description": "logs", "default_state": "hot", "states": [
+				{"name": "hot", "actions": [{"action": "delete"}, {"action": "snapshot", "snapshot": {"repository": "(known after apply)", "snapshot": "logs"}}]}
+			]}`,
+			expectedErr: "the delete action must be the last action of the state",
+		},
+		{
+			name: "unreachable state",
+			config: `{"policy_id": "logs", "description": "logs", "default_state": "hot", "states": [
+				{"name": "hot"},
+				{"name": "cold"}
+			]}`,
+			expectedWarnings: []string{"State 'cold' cannot be reached"},
+		},
+		{
+			name: "unknown transition target",
+			config: `{"policy_id": "logs", "description": "logs", "default_state": "hot", "states": [
+				{"name": "hot", "transitions": [{"state_name": "(known after apply)"}]},
+				{"name": "cold"}
+			]}`,
+		},
+		{
+			name: "unknown state name",
+			config: `{"policy_id": "logs", "description": "logs", "default_state": "hot", "states": [
+				{"name": "hot", "transitions": [{"state_name": "cold"}]},
+				{"name": "(known after apply)"}
+			]}`,
+		},
+		{
+			name: "duplicate state with an unknown state name",
+			config: `{"policy_id": "logs", "description": "logs", "default_state": "hot", "states": [
+				{"name": "hot"},
+				{"name": "hot"},
+				{"name": "(known after apply)"}
+			]}`,
+			expectedErr: "State 'hot' is defined more than once",
+		},
+		{
+			name: "unreachable state in the policy json",
+			config: `{"policy_id": "logs", "policy_json": "{\"description\": \"logs\", \"default_state\": \"hot\", \"states\": [{\"name\": \"hot\"}, {\"name\": \"cold\"}]}"}`,
+			expectedWarnings: []string{"State 'cold' cannot be reached"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := tftypes.ValueFromJSON([]byte(tt.config), configType)
+			if err != nil {
+				t.Fatalf("Failed to parse the config: %s", err.Error())
+			}
+
+			raw, err = tftypes.Transform(raw, func(attrPath *tftypes.AttributePath, value tftypes.Value) (tftypes.Value, error) {
+				var str string
+				if value.Type().Is(tftypes.String) && value.IsKnown() && !value.IsNull() && value.As(&str) == nil && str == unknownConfigValue {
+					return tftypes.NewValue(tftypes.String, tftypes.UnknownValue), nil
+				}
+				return value, nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to set the unknown values of the config: %s", err.Error())
+			}
+
+			resp := resource.ValidateConfigResponse{}
+			ismPolicy.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw}}, &resp)
+
+			errs := resp.Diagnostics.Errors()
+			if tt.expectedErr == "" && len(errs) > 0 {
+				t.Fatalf("Expected no error, got %v", errs)
+			}
+			if tt.expectedErr != "" && (len(errs) == 0 || !strings.Contains(errs[0].Detail(), tt.expectedErr)) {
+				t.Fatalf("Expected error containing '%s', got %v", tt.expectedErr, errs)
+			}
+
+			warnings := resp.Diagnostics.Warnings()
+			if len(warnings) != len(tt.expectedWarnings) {
+				t.Fatalf("Expected %d warnings, got %v", len(tt.expectedWarnings), warnings)
+			}
+			for idx, expected := range tt.expectedWarnings {
+				if warnings[idx].Severity() != diag.SeverityWarning || !strings.Contains(warnings[idx].Detail(), expected) {
+					t.Errorf("Expected warning containing '%s', got '%s'", expected, warnings[idx].Detail())
+				}
+			}
+		})
+	}
+}
+
+func TestIsmPolicySdkStateUpgradeWithoutDiff(t *testing.T) {
+	ctx := context.Background()
+	server := NewFrameworkProviderServer()()
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get the schema: %s", err.Error())
+	}
+	stateType := schemaResp.ResourceSchemas["opensearch_ism_policy"].ValueType()
+
+	sdkState := `{
+		"id": "logs",
+		"policy_id": "logs",
+		"policy_json": "",
+		"description": "logs policy",
+		"default_state": "hot",
+		"switched_managed_indices": 0,
+		"ism_template": [{"priority": 0, "index_patterns": ["logs-*"]}],
+		"error_notification": [],
+		"update_managed_indices": [],
+		"states": [
+			{
+				"name": "hot",
+				"actions": [
+					{
+						"timeout": "", "retry": [], "action": "rollover", "index_priority": 0, "replica_count": 0,
+						"rollover": [{"min_size": "5gb", "min_primary_shard_size": "", "min_doc_count": 0, "min_index_age": "", "copy_alias": false}],
+						"force_merge": [], "shrink": [], "allocation": [], "snapshot": [], "rollup": [], "notification": [], "transform": []
+					},
+					{
+						"timeout": "", "retry": [], "action": "shrink", "index_priority": 0, "replica_count": 0,
+						"shrink": [{"num_new_shards": 1, "max_shard_size": "", "percentage_of_source_shards": 0, "target_index_name_suffix": "", "force_unsafe": false}],
+						"rollover": [], "force_merge": [], "allocation": [], "snapshot": [], "rollup": [], "notification": [], "transform": []
+					},
+					{
+						"timeout": "", "retry": [], "action": "allocation", "index_priority": 0, "replica_count": 0,
+						"allocation": [{"require": {"box_type": "warm"}, "include": {}, "exclude": {}, "wait_for": false}],
+						"rollover": [], "force_merge": [], "shrink": [], "snapshot": [], "rollup": [], "notification": [], "transform": []
+					},
+					{
+						"timeout": "", "retry": [], "action": "index_priority", "index_priority": 0, "replica_count": 0,
+						"rollover": [], "force_merge": [], "shrink": [], "allocation": [], "snapshot": [], "rollup": [], "notification": [], "transform": []
+					}
+				],
+				"transitions": [
+					{
+						"state_name": "delete",
+						"conditions": [{"min_index_age": "30d", "min_rollover_age": "", "min_doc_count": 0, "min_size": "", "cron": []}]
+					}
+				]
+			},
+			{
+				"name": "delete",
+				"actions": [],
+				"transitions": []
+			}
+		],
+		"timeouts": null
+	}`
+
+	upgradeResp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: "opensearch_ism_policy",
+		Version:  1,
+		RawState: &tfprotov6.RawState{JSON: []byte(sdkState)},
+	})
+	if err != nil || len(upgradeResp.Diagnostics) > 0 {
+		t.Fatalf("Failed to upgrade the state: %v %v", err, upgradeResp.Diagnostics)
+	}
+	prior, err := upgradeResp.UpgradedState.Unmarshal(stateType)
+	if err != nil {
+		t.Fatalf("Failed to read the upgraded state: %s", err.Error())
+	}
+
+	tests := []struct {
+		name    string
+		omitted []string
+	}{
+		{
+			name: "arguments set to their zero value",
+		},
+		{
+			name:    "arguments omitted",
+			omitted: []string{"priority", "copy_alias", "force_unsafe", "wait_for"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//The configuration matching the state, without the computed attributes and the omitted arguments
+			nulled := append([]string{"id", "switched_managed_indices"}, tt.omitted...)
+			config, err := tftypes.Transform(prior, func(attrPath *tftypes.AttributePath, value tftypes.Value) (tftypes.Value, error) {
+				steps := attrPath.Steps()
+				if len(steps) == 0 {
+					return value, nil
+				}
+				name, isName := steps[len(steps) - 1].(tftypes.AttributeName)
+				for _, attribute := range nulled {
+					if isName && string(name) == attribute {
+						return tftypes.NewValue(value.Type(), nil), nil
+					}
+				}
+				return value, nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to build the config: %s", err.Error())
+			}
+
+			//Like terraform, the proposed state takes the unset computed attributes from the prior state
+			proposed, err := tftypes.Transform(config, func(attrPath *tftypes.AttributePath, value tftypes.Value) (tftypes.Value, error) {
+				if !value.IsNull() {
+					return value, nil
+				}
+				priorValue, _, walkErr := tftypes.WalkAttributePath(prior, attrPath)
+				if walkErr != nil {
+					return value, nil
+				}
+				return priorValue.(tftypes.Value), nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to build the proposed state: %s", err.Error())
+			}
+
+			configValue, _ := tfprotov6.NewDynamicValue(stateType, config)
+			proposedValue, _ := tfprotov6.NewDynamicValue(stateType, proposed)
+			planResp, err := server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+				TypeName:         "opensearch_ism_policy",
+				PriorState:       upgradeResp.UpgradedState,
+				ProposedNewState: &proposedValue,
+				Config:           &configValue,
+			})
+			if err != nil || len(planResp.Diagnostics) > 0 {
+				t.Fatalf("Failed to plan: %v %v", err, planResp.Diagnostics)
+			}
+
+			planned, err := planResp.PlannedState.Unmarshal(stateType)
+			if err != nil {
+				t.Fatalf("Failed to read the plan: %s", err.Error())
+			}
+
+			diffs, err := planned.Diff(prior)
+			if err != nil {
+				t.Fatalf("Failed to compare the plan with the state: %s", err.Error())
+			}
+			for _, diff := range diffs {
+				t.Errorf("Expected no diff, got %s: %v -> %v", diff.Path.String(), diff.Value2, diff.Value1)
+			}
+		})
+	}
+}
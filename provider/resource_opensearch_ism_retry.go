@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func resourceOpensearchIsmRetry() *schema.Resource {
+	return &schema.Resource{
+		Description: "Retry of the failed ism steps of indices. The retry is performed when the resource is created and performed again whenever one of its arguments changes. Destroying the resource has no effect on the indices.",
+		CreateContext: resourceOpensearchIsmRetryCreate,
+		ReadContext:   resourceOpensearchIsmRetryRead,
+		DeleteContext: resourceOpensearchIsmRetryDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Description: "Name of the indices to retry. Wildcards and comma separated lists are supported. Only the indices that are in a failed step are retried.",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"state": {
+				Description: "State of the policy to restart the indices from. If omitted, the failed action is retried.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotEmpty,
+			},
+			"triggers": {
+				Description: "Arbitrary values that perform the retry again when they change, such as the version of the fixed policy.",
+				Type:     schema.TypeMap,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"retried_indices": {
+				Description: "Indices that were in a failed step and were retried.",
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+//The retry api reports the indices that are not in a failed step as failures, so only the failed indices are retried
+func resourceOpensearchIsmRetryCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cli := meta.(OpensearchClient)
+	index := d.Get("index").(string)
+
+	explanations, err := cli.GetRequestContext(ctx).ExplainIsmIndices(index)
+	if err != nil {
+		return diag.Errorf("Error retrieving the ism status of indices '%s': %s", index, err.Error())
+	}
+
+	toRetry := []string{}
+	for name, explanation := range explanations {
+		if explanation.IsManaged() && explanation.IsFailed() {
+			toRetry = append(toRetry, name)
+		}
+	}
+
+	if len(toRetry) == 0 {
+		tflog.Info(ctx, fmt.Sprintf("None of the indices '%s' are in a failed ism step, there is nothing to retry", index))
+	}
+
+	retry := IsmRetryModel{
+		State: d.Get("state").(string),
+	}
+	update, err := cli.RetryIsmOfIndices(ctx, toRetry, retry)
+	if err == nil {
+		err = update.GetFailuresError()
+	}
+	if err != nil {
+		return diag.Errorf("Error retrying the failed ism steps of indices '%s': %s", index, err.Error())
+	}
+
+	d.SetId(index)
+	d.Set("retried_indices", toRetry)
+	return resourceOpensearchIsmRetryRead(ctx, d, meta)
+}
+
+//The retry is a one-off operation, there is nothing to read back from the cluster
+func resourceOpensearchIsmRetryRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func resourceOpensearchIsmRetryDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
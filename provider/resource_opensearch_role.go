@@ -1,289 +1,529 @@
 package provider
 
 import (
-	"errors"
+	"context"
 	"fmt"
-
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func resourceOpensearchRole() *schema.Resource {
-	return &schema.Resource{
-		Description: "Opensearch role.",
-		Create: resourceOpensearchRoleCreate,
-		Update: resourceOpensearchRoleUpdate,
-		Read:   resourceOpensearchRoleRead,
-		Delete: resourceOpensearchRoleDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Description: "Name of the role.",
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     true,
-				ValidateFunc: validation.StringIsNotEmpty,
+type roleResource struct {
+	data *frameworkProviderData
+}
+
+type roleResourceModel struct {
+	Id                 types.String `tfsdk:"id"`
+	Name               types.String `tfsdk:"name"`
+	ClusterPermissions types.Set    `tfsdk:"cluster_permissions"`
+	TenantPermissions  types.Set    `tfsdk:"tenant_permissions"`
+	IndexPermissions   types.Set    `tfsdk:"index_permissions"`
+	Timeouts           types.Object `tfsdk:"timeouts"`
+}
+
+type tenantPermissionResourceModel struct {
+	TenantPatterns types.Set `tfsdk:"tenant_patterns"`
+	AllowedActions types.Set `tfsdk:"allowed_actions"`
+}
+
+var tenantPermissionAttrTypes = map[string]attr.Type{
+	"tenant_patterns": types.SetType{ElemType: types.StringType},
+	"allowed_actions": types.SetType{ElemType: types.StringType},
+}
+
+type indexPermissionResourceModel struct {
+	IndexPatterns         types.Set    `tfsdk:"index_patterns"`
+	AllowedActions        types.Set    `tfsdk:"allowed_actions"`
+	MaskedFields          types.Set    `tfsdk:"masked_fields"`
+	DocumentLevelSecurity types.String `tfsdk:"document_level_security"`
+	FieldLevelSecurity    types.Set    `tfsdk:"field_level_security"`
+}
+
+var indexPermissionAttrTypes = map[string]attr.Type{
+	"index_patterns":          types.SetType{ElemType: types.StringType},
+	"allowed_actions":         types.SetType{ElemType: types.StringType},
+	"masked_fields":           types.SetType{ElemType: types.StringType},
+	"document_level_security": types.StringType,
+	"field_level_security":    types.SetType{ElemType: types.StringType},
+}
+
+func NewRoleResource() resource.Resource {
+	return &roleResource{}
+}
+
+func (r *roleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role"
+}
+
+func (r *roleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Opensearch role.",
+		Version:             1,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The ID of this resource.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"cluster_permissions": {
-				Description: "Permissions for cluster wide actions the role has.",
-				Type:     schema.TypeSet,
-				Optional: true,
-				ForceNew: false,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the role.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
 				},
 			},
-			"tenant_permissions": {
-				Description: "Permissions for tenant access the role has.",
-                Type: schema.TypeSet,
-                Optional: true,
-                ForceNew: false,
-                Elem: &schema.Resource{
-                    Schema: map[string]*schema.Schema{
-                        "tenant_patterns": {
-                            Type: schema.TypeSet,
-                            Optional: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-                        },
-                        "allowed_actions": {
-                            Type: schema.TypeSet,
-                            Optional: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-                        },
-                    },
-                },
+			"cluster_permissions": schema.SetAttribute{
+				MarkdownDescription: "Permissions for cluster wide actions the role has.",
+				ElementType:         types.StringType,
+				Optional:            true,
 			},
-			"index_permissions": {
-				Description: "Permissions for index access the role has.",
-                Type: schema.TypeSet,
-                Optional: true,
-                ForceNew: false,
-                Elem: &schema.Resource{
-                    Schema: map[string]*schema.Schema{
-                        "index_patterns": {
-                            Type: schema.TypeSet,
-                            Optional: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-                        },
-                        "allowed_actions": {
-                            Type: schema.TypeSet,
-                            Optional: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-                        },
-						"masked_fields": {
-							Type:     schema.TypeSet,
-							Optional: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
+		},
+		Blocks: map[string]schema.Block{
+			"tenant_permissions": schema.SetNestedBlock{
+				MarkdownDescription: "Permissions for tenant access the role has.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"tenant_patterns": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
 						},
-						"document_level_security": {
-							Type:     schema.TypeString,
-							Optional: true,
+						"allowed_actions": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"index_permissions": schema.SetNestedBlock{
+				MarkdownDescription: "Permissions for index access the role has.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"index_patterns": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+						"allowed_actions": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
 						},
-						"field_level_security": {
-							Type:     schema.TypeSet,
+						"masked_fields": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+						"document_level_security": schema.StringAttribute{
 							Optional: true,
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
 						},
-                    },
-                },
+						"field_level_security": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+					},
+				},
 			},
+			"timeouts": frameworkTimeoutsBlock(),
 		},
 	}
 }
 
-func tenantPermissionSchemaToModel(d map[string]interface{}) TenantPermissionModel {
-	model := TenantPermissionModel{
-		TenantPatterns: []string{},
-		AllowedActions: []string{},
-	}
+func (r *roleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data, diags := getFrameworkProviderData(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.data = data
+}
 
-	tenantPatterns, tenantPatternsExist := d["tenant_patterns"]
-	if tenantPatternsExist {
-		for _, val := range (tenantPatterns.(*schema.Set)).List() {
-			tenantPattern := val.(string)
-			model.TenantPatterns = append(model.TenantPatterns, tenantPattern)
-		}
+func (r *roleResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				state := roleResourceModel{}
+				resp.Diagnostics.Append(getSdkState(ctx, req, resp, &state)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				var diags diag.Diagnostics
+				state.ClusterPermissions = sdkSetToFrameworkSet(ctx, state.ClusterPermissions)
+				state.TenantPermissions, diags = sdkTenantPermissionsToFrameworkSet(ctx, state.TenantPermissions)
+				resp.Diagnostics.Append(diags...)
+				state.IndexPermissions, diags = sdkIndexPermissionsToFrameworkSet(ctx, state.IndexPermissions)
+				resp.Diagnostics.Append(diags...)
+				state.Timeouts, diags = sdkTimeoutsToFrameworkTimeouts(ctx, state.Timeouts)
+				resp.Diagnostics.Append(diags...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			},
+		},
 	}
+}
 
-	allowedActions, allowedActionsExist := d["allowed_actions"]
-	if allowedActionsExist {
-		for _, val := range (allowedActions.(*schema.Set)).List() {
-			allowedAction := val.(string)
-			model.AllowedActions = append(model.AllowedActions, allowedAction)
-		}
-	}
+func (r *roleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func tenantPermissionResourceModelToModel(ctx context.Context, m tenantPermissionResourceModel) (TenantPermissionModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var setDiags diag.Diagnostics
 
-	return model
+	model := TenantPermissionModel{}
+
+	model.TenantPatterns, setDiags = frameworkSetToStrings(ctx, m.TenantPatterns)
+	diags.Append(setDiags...)
+	model.AllowedActions, setDiags = frameworkSetToStrings(ctx, m.AllowedActions)
+	diags.Append(setDiags...)
+
+	return model, diags
 }
 
-func indexPermissionSchemaToModel(d map[string]interface{}) IndexPermissionModel {
+func indexPermissionResourceModelToModel(ctx context.Context, m indexPermissionResourceModel) (IndexPermissionModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var setDiags diag.Diagnostics
+
 	model := IndexPermissionModel{
-		IndexPatterns:         []string{},
-		AllowedActions:        []string{},
-		MaskedFields:          []string{},
-		DocumentLevelSecurity: "",
-		FieldLevelSecurity:    []string{},
-	}
-
-	indexPatterns, indexPatternsExist := d["index_patterns"]
-	if indexPatternsExist {
-		for _, val := range (indexPatterns.(*schema.Set)).List() {
-			indexPattern := val.(string)
-			model.IndexPatterns = append(model.IndexPatterns, indexPattern)
-		}
+		DocumentLevelSecurity: m.DocumentLevelSecurity.ValueString(),
 	}
 
-	allowedActions, allowedActionsExist := d["allowed_actions"]
-	if allowedActionsExist {
-		for _, val := range (allowedActions.(*schema.Set)).List() {
-			allowedAction := val.(string)
-			model.AllowedActions = append(model.AllowedActions, allowedAction)
-		}
+	model.IndexPatterns, setDiags = frameworkSetToStrings(ctx, m.IndexPatterns)
+	diags.Append(setDiags...)
+	model.AllowedActions, setDiags = frameworkSetToStrings(ctx, m.AllowedActions)
+	diags.Append(setDiags...)
+	model.MaskedFields, setDiags = frameworkSetToStrings(ctx, m.MaskedFields)
+	diags.Append(setDiags...)
+	model.FieldLevelSecurity, setDiags = frameworkSetToStrings(ctx, m.FieldLevelSecurity)
+	diags.Append(setDiags...)
+
+	return model, diags
+}
+
+func roleResourceModelToModel(ctx context.Context, m roleResourceModel) (RoleModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var setDiags diag.Diagnostics
+
+	model := RoleModel{
+		Name:              m.Name.ValueString(),
+		TenantPermissions: []TenantPermissionModel{},
+		IndexPermissions:  []IndexPermissionModel{},
+	}
+
+	model.ClusterPermissions, setDiags = frameworkSetToStrings(ctx, m.ClusterPermissions)
+	diags.Append(setDiags...)
+
+	tenantPermissions := []tenantPermissionResourceModel{}
+	if !m.TenantPermissions.IsNull() && !m.TenantPermissions.IsUnknown() {
+		diags.Append(m.TenantPermissions.ElementsAs(ctx, &tenantPermissions, false)...)
+	}
+	for _, val := range tenantPermissions {
+		permission, permissionDiags := tenantPermissionResourceModelToModel(ctx, val)
+		diags.Append(permissionDiags...)
+		model.TenantPermissions = append(model.TenantPermissions, permission)
+	}
+
+	indexPermissions := []indexPermissionResourceModel{}
+	if !m.IndexPermissions.IsNull() && !m.IndexPermissions.IsUnknown() {
+		diags.Append(m.IndexPermissions.ElementsAs(ctx, &indexPermissions, false)...)
+	}
+	for _, val := range indexPermissions {
+		permission, permissionDiags := indexPermissionResourceModelToModel(ctx, val)
+		diags.Append(permissionDiags...)
+		model.IndexPermissions = append(model.IndexPermissions, permission)
+	}
+
+	return model, diags
+}
+
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
 	}
 
-	maskedFields, maskedFieldsExist := d["masked_fields"]
-	if maskedFieldsExist {
-		for _, val := range (maskedFields.(*schema.Set)).List() {
-			maskedField := val.(string)
-			model.MaskedFields = append(model.MaskedFields, maskedField)
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for idx, _ := range sortedA {
+		if sortedA[idx] != sortedB[idx] {
+			return false
 		}
 	}
 
-	documentLevelSecurity, documentLevelSecurityExist := d["document_level_security"]
-	if documentLevelSecurityExist {
-		model.DocumentLevelSecurity = documentLevelSecurity.(string)
+	return true
+}
+
+//Permissions in the prior state that are equivalent to the ones returned by the api are kept as is
+//so that unset and empty arguments of the permission blocks are not swapped for one another
+func tenantPermissionsToFrameworkSet(ctx context.Context, permissions []TenantPermissionModel, prior types.Set) (types.Set, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	priorPermissions := []tenantPermissionResourceModel{}
+	if !prior.IsNull() && !prior.IsUnknown() {
+		diags.Append(prior.ElementsAs(ctx, &priorPermissions, false)...)
 	}
 
-	fieldLevelSecurity, fieldLevelSecurityExist := d["field_level_security"]
-	if fieldLevelSecurityExist {
-		for _, val := range (fieldLevelSecurity.(*schema.Set)).List() {
-			field := val.(string)
-			model.FieldLevelSecurity = append(model.FieldLevelSecurity, field)
+	elems := []tenantPermissionResourceModel{}
+	for _, permission := range permissions {
+		tenantPatterns, setDiags := stringsToFrameworkSet(ctx, permission.TenantPatterns, types.SetNull(types.StringType))
+		diags.Append(setDiags...)
+		allowedActions, setDiags := stringsToFrameworkSet(ctx, permission.AllowedActions, types.SetNull(types.StringType))
+		diags.Append(setDiags...)
+		elem := tenantPermissionResourceModel{
+			TenantPatterns: tenantPatterns,
+			AllowedActions: allowedActions,
 		}
+
+		for _, priorPermission := range priorPermissions {
+			priorModel, priorDiags := tenantPermissionResourceModelToModel(ctx, priorPermission)
+			diags.Append(priorDiags...)
+			if sameStrings(priorModel.TenantPatterns, permission.TenantPatterns) && sameStrings(priorModel.AllowedActions, permission.AllowedActions) {
+				elem = priorPermission
+				break
+			}
+		}
+
+		elems = append(elems, elem)
 	}
 
-	return model
+	if diags.HasError() {
+		return prior, diags
+	}
+
+	set, setDiags := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: tenantPermissionAttrTypes}, elems)
+	diags.Append(setDiags...)
+	return set, diags
 }
 
-func roleSchemaToModel(d *schema.ResourceData) RoleModel {
-	model := RoleModel{
-		Name:               "",
-		ClusterPermissions: []string{},
-		TenantPermissions:  []TenantPermissionModel{},
-		IndexPermissions:   []IndexPermissionModel{},
+func indexPermissionsToFrameworkSet(ctx context.Context, permissions []IndexPermissionModel, prior types.Set) (types.Set, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	priorPermissions := []indexPermissionResourceModel{}
+	if !prior.IsNull() && !prior.IsUnknown() {
+		diags.Append(prior.ElementsAs(ctx, &priorPermissions, false)...)
 	}
 
-	name, _ := d.GetOk("name")
-	model.Name = name.(string)
+	elems := []indexPermissionResourceModel{}
+	for _, permission := range permissions {
+		indexPatterns, setDiags := stringsToFrameworkSet(ctx, permission.IndexPatterns, types.SetNull(types.StringType))
+		diags.Append(setDiags...)
+		allowedActions, setDiags := stringsToFrameworkSet(ctx, permission.AllowedActions, types.SetNull(types.StringType))
+		diags.Append(setDiags...)
+		maskedFields, setDiags := stringsToFrameworkSet(ctx, permission.MaskedFields, types.SetNull(types.StringType))
+		diags.Append(setDiags...)
+		fieldLevelSecurity, setDiags := stringsToFrameworkSet(ctx, permission.FieldLevelSecurity, types.SetNull(types.StringType))
+		diags.Append(setDiags...)
+		elem := indexPermissionResourceModel{
+			IndexPatterns:         indexPatterns,
+			AllowedActions:        allowedActions,
+			MaskedFields:          maskedFields,
+			DocumentLevelSecurity: stringToFrameworkString(permission.DocumentLevelSecurity, types.StringNull()),
+			FieldLevelSecurity:    fieldLevelSecurity,
+		}
 
-	clusterPermissions, clusterPermissionsExist := d.GetOk("cluster_permissions")
-	if clusterPermissionsExist {
-		for _, val := range (clusterPermissions.(*schema.Set)).List() {
-			permission := val.(string)
-			model.ClusterPermissions = append(model.ClusterPermissions, permission)
+		for _, priorPermission := range priorPermissions {
+			priorModel, priorDiags := indexPermissionResourceModelToModel(ctx, priorPermission)
+			diags.Append(priorDiags...)
+			if sameStrings(priorModel.IndexPatterns, permission.IndexPatterns) &&
+				sameStrings(priorModel.AllowedActions, permission.AllowedActions) &&
+				sameStrings(priorModel.MaskedFields, permission.MaskedFields) &&
+				priorModel.DocumentLevelSecurity == permission.DocumentLevelSecurity &&
+				sameStrings(priorModel.FieldLevelSecurity, permission.FieldLevelSecurity) {
+				elem = priorPermission
+				break
+			}
 		}
+
+		elems = append(elems, elem)
 	}
 
-	tenantPermissions, tenantPermissionsExist := d.GetOk("tenant_permissions")
-	if tenantPermissionsExist {
-		for _, val := range (tenantPermissions.(*schema.Set)).List() {
-			model.TenantPermissions = append(model.TenantPermissions, tenantPermissionSchemaToModel(val.(map[string]interface{})))
+	if diags.HasError() {
+		return prior, diags
+	}
+
+	set, setDiags := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: indexPermissionAttrTypes}, elems)
+	diags.Append(setDiags...)
+	return set, diags
+}
+
+func sdkTenantPermissionsToFrameworkSet(ctx context.Context, permissions types.Set) (types.Set, diag.Diagnostics) {
+	elems := []tenantPermissionResourceModel{}
+	diags := permissions.ElementsAs(ctx, &elems, false)
+	if diags.HasError() {
+		return permissions, diags
+	}
+
+	for idx, elem := range elems {
+		elems[idx] = tenantPermissionResourceModel{
+			TenantPatterns: sdkSetToFrameworkSet(ctx, elem.TenantPatterns),
+			AllowedActions: sdkSetToFrameworkSet(ctx, elem.AllowedActions),
 		}
 	}
 
-	indexPermissions, indexPermissionsExist := d.GetOk("index_permissions")
-	if indexPermissionsExist {
-		for _, val := range (indexPermissions.(*schema.Set)).List() {
-			model.IndexPermissions = append(model.IndexPermissions, indexPermissionSchemaToModel(val.(map[string]interface{})))
+	set, setDiags := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: tenantPermissionAttrTypes}, elems)
+	diags.Append(setDiags...)
+	return set, diags
+}
+
+func sdkIndexPermissionsToFrameworkSet(ctx context.Context, permissions types.Set) (types.Set, diag.Diagnostics) {
+	elems := []indexPermissionResourceModel{}
+	diags := permissions.ElementsAs(ctx, &elems, false)
+	if diags.HasError() {
+		return permissions, diags
+	}
+
+	for idx, elem := range elems {
+		elems[idx] = indexPermissionResourceModel{
+			IndexPatterns:         sdkSetToFrameworkSet(ctx, elem.IndexPatterns),
+			AllowedActions:        sdkSetToFrameworkSet(ctx, elem.AllowedActions),
+			MaskedFields:          sdkSetToFrameworkSet(ctx, elem.MaskedFields),
+			DocumentLevelSecurity: sdkStringToFrameworkString(elem.DocumentLevelSecurity),
+			FieldLevelSecurity:    sdkSetToFrameworkSet(ctx, elem.FieldLevelSecurity),
 		}
 	}
 
-	return model
+	set, setDiags := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: indexPermissionAttrTypes}, elems)
+	diags.Append(setDiags...)
+	return set, diags
 }
 
-func resourceOpensearchRoleCreate(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	role := roleSchemaToModel(d)
+func (r *roleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	plan := roleResourceModel{}
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkTimeout(ctx, plan.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	role, diags := roleResourceModelToModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	err := cli.GetRequestContext().UpsertRole(role)
+	err := cli.GetRequestContext(ctx).UpsertRole(role)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error creating role '%s': %s", role.Name, err.Error()))
+		resp.Diagnostics.AddError("Error creating role", fmt.Sprintf("Error creating role '%s': %s", role.Name, err.Error()))
+		return
 	}
 
-	d.SetId(role.Name)
-	return resourceOpensearchRoleRead(d, meta)
+	plan.Id = types.StringValue(role.Name)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceOpensearchRoleUpdate(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	role := roleSchemaToModel(d)
+func (r *roleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	plan := roleResourceModel{}
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkTimeout(ctx, plan.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	role, diags := roleResourceModelToModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	err := cli.GetRequestContext().UpsertRole(role)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := cli.GetRequestContext(ctx).UpsertRole(role)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error updating existing role '%s': %s", role.Name, err.Error()))
+		resp.Diagnostics.AddError("Error updating role", fmt.Sprintf("Error updating existing role '%s': %s", role.Name, err.Error()))
+		return
 	}
 
-	return resourceOpensearchRoleRead(d, meta)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceOpensearchRoleRead(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	name := d.Id()
+func (r *roleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	state := roleResourceModel{}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkTimeout(ctx, state.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	name := state.Id.ValueString()
+	role, err := cli.GetRequestContext(ctx).GetRole(name)
+	if IsNotFound(err) {
+		resp.Diagnostics.AddWarning("Role not found", fmt.Sprintf("Role '%s' was not found, removing it from the state", name))
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
-	role, err := cli.GetRequestContext().GetRole(name)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error retrieving existing role '%s': %s", name, err.Error()))
-	}
-
-	d.Set("name", name)
-	d.Set("cluster_permissions", role.ClusterPermissions)
-	
-    tenantPermissions := make([]map[string]interface{}, 0)
-    for _, v := range role.TenantPermissions {
-        tenantPermissions = append(tenantPermissions, map[string]interface{}{
-            "tenant_patterns": v.TenantPatterns,
-            "allowed_actions": v.AllowedActions,
-        })
-    }
-    d.Set("tenant_permissions", tenantPermissions)
-
-    indexPermissions := make([]map[string]interface{}, 0)
-    for _, v := range role.IndexPermissions {
-        indexPermissions = append(indexPermissions, map[string]interface{}{
-            "index_patterns": v.IndexPatterns,
-            "allowed_actions": v.AllowedActions,
-			"masked_fields": v.MaskedFields,
-			"document_level_security": v.DocumentLevelSecurity,
-			"field_level_security": v.FieldLevelSecurity,
-        })
-    }
-    d.Set("index_permissions", indexPermissions)
-
-	return nil
+		resp.Diagnostics.AddError("Error retrieving role", fmt.Sprintf("Error retrieving existing role '%s': %s", name, err.Error()))
+		return
+	}
+
+	state.Name = types.StringValue(name)
+	state.ClusterPermissions, diags = stringsToFrameworkSet(ctx, role.ClusterPermissions, state.ClusterPermissions)
+	resp.Diagnostics.Append(diags...)
+	state.TenantPermissions, diags = tenantPermissionsToFrameworkSet(ctx, role.TenantPermissions, state.TenantPermissions)
+	resp.Diagnostics.Append(diags...)
+	state.IndexPermissions, diags = indexPermissionsToFrameworkSet(ctx, role.IndexPermissions, state.IndexPermissions)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-func resourceOpensearchRoleDelete(d *schema.ResourceData, meta interface{}) error {
-	name := d.Id()
-	cli := meta.(OpensearchClient)
+func (r *roleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	state := roleResourceModel{}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	err := cli.GetRequestContext().DeleteRole(name)
-	if err != nil {
-		return errors.New(fmt.Sprintf("Error deleting existing role '%s': %s", name, err.Error()))
+	timeout, diags := getFrameworkTimeout(ctx, state.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	return nil
-}
\ No newline at end of file
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	name := state.Id.ValueString()
+	err := cli.GetRequestContext(ctx).DeleteRole(name)
+	if err != nil && !IsNotFound(err) {
+		resp.Diagnostics.AddError("Error deleting role", fmt.Sprintf("Error deleting existing role '%s': %s", name, err.Error()))
+	}
+}
@@ -1,150 +1,261 @@
 package provider
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func resourceOpensearchRoleMapping() *schema.Resource {
-	return &schema.Resource{
-		Description: "Opensearch role mapping to map backend roles, users and hosts to a given role.",
-		Create: resourceOpensearchRoleMappingCreate,
-		Update: resourceOpensearchRoleMappingUpdate,
-		Read:   resourceOpensearchRoleMappingRead,
-		Delete: resourceOpensearchRoleMappingDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
-		Schema: map[string]*schema.Schema{
-			"role": {
-				Description:  "Role that things should be mapped to.",
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     true,
-				ValidateFunc: validation.StringIsNotEmpty,
-			},
-			"backend_roles": {
-				Description: "Backend roles to map to the role.",
-				Type:     schema.TypeSet,
-				Optional: true,
-				ForceNew: false,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+type roleMappingResource struct {
+	data *frameworkProviderData
+}
+
+type roleMappingResourceModel struct {
+	Id           types.String `tfsdk:"id"`
+	Role         types.String `tfsdk:"role"`
+	BackendRoles types.Set    `tfsdk:"backend_roles"`
+	Hosts        types.Set    `tfsdk:"hosts"`
+	Users        types.Set    `tfsdk:"users"`
+	Timeouts     types.Object `tfsdk:"timeouts"`
+}
+
+func NewRoleMappingResource() resource.Resource {
+	return &roleMappingResource{}
+}
+
+func (r *roleMappingResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role_mapping"
+}
+
+func (r *roleMappingResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Opensearch role mapping to map backend roles, users and hosts to a given role.",
+		Version:             1,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The ID of this resource.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"hosts": {
-				Description: "Hosts to map to the role.",
-				Type:     schema.TypeSet,
-				Optional: true,
-				ForceNew: false,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+			"role": schema.StringAttribute{
+				MarkdownDescription: "Role that things should be mapped to.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
 				},
-			},
-			"users": {
-				Description: "Users to map to the role.",
-				Type:     schema.TypeSet,
-				Optional: true,
-				ForceNew: false,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
 				},
 			},
+			"backend_roles": schema.SetAttribute{
+				MarkdownDescription: "Backend roles to map to the role.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"hosts": schema.SetAttribute{
+				MarkdownDescription: "Hosts to map to the role.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"users": schema.SetAttribute{
+				MarkdownDescription: "Users to map to the role.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": frameworkTimeoutsBlock(),
+		},
+	}
+}
+
+func (r *roleMappingResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data, diags := getFrameworkProviderData(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.data = data
+}
+
+func (r *roleMappingResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				state := roleMappingResourceModel{}
+				resp.Diagnostics.Append(getSdkState(ctx, req, resp, &state)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+
+				var diags diag.Diagnostics
+				state.BackendRoles = sdkSetToFrameworkSet(ctx, state.BackendRoles)
+				state.Hosts = sdkSetToFrameworkSet(ctx, state.Hosts)
+				state.Users = sdkSetToFrameworkSet(ctx, state.Users)
+				state.Timeouts, diags = sdkTimeoutsToFrameworkTimeouts(ctx, state.Timeouts)
+				resp.Diagnostics.Append(diags...)
+
+				resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			},
 		},
 	}
 }
 
-func roleMappingSchemaToModel(d *schema.ResourceData) RoleMappingModel {
+func (r *roleMappingResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func roleMappingResourceModelToModel(ctx context.Context, m roleMappingResourceModel) (RoleMappingModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var setDiags diag.Diagnostics
+
 	model := RoleMappingModel{
-		Role: "", 
-		BackendRoles: []string{}, 
-		Hosts: []string{}, 
-		Users: []string{},
+		Role: m.Role.ValueString(),
 	}
 
-	role, _ := d.GetOk("role")
-	model.Role = role.(string)
+	model.BackendRoles, setDiags = frameworkSetToStrings(ctx, m.BackendRoles)
+	diags.Append(setDiags...)
+	model.Hosts, setDiags = frameworkSetToStrings(ctx, m.Hosts)
+	diags.Append(setDiags...)
+	model.Users, setDiags = frameworkSetToStrings(ctx, m.Users)
+	diags.Append(setDiags...)
+
+	return model, diags
+}
 
-	backendRoles, backendRolesExist := d.GetOk("backend_roles")
-	if backendRolesExist {
-		for _, val := range (backendRoles.(*schema.Set)).List() {
-			role := val.(string)
-			model.BackendRoles = append(model.BackendRoles, role)
-		}
+func (r *roleMappingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	plan := roleMappingResourceModel{}
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	hosts, hostsExist := d.GetOk("hosts")
-	if hostsExist {
-		for _, val := range (hosts.(*schema.Set)).List() {
-			host := val.(string)
-			model.Hosts = append(model.Hosts, host)
-		}
+	timeout, diags := getFrameworkTimeout(ctx, plan.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	roleMapping, diags := roleMappingResourceModelToModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	users, usersExist := d.GetOk("users")
-	if usersExist {
-		for _, val := range (users.(*schema.Set)).List() {
-			user := val.(string)
-			model.Users = append(model.Users, user)
-		}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := cli.GetRequestContext(ctx).UpsertRoleMapping(roleMapping)
+	if err != nil {
+		resp.Diagnostics.AddError("Error creating role mapping", fmt.Sprintf("Error creating role mapping for role '%s': %s", roleMapping.Role, err.Error()))
+		return
 	}
 
-	return model
+	plan.Id = types.StringValue(roleMapping.Role)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceOpensearchRoleMappingCreate(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	roleMapping := roleMappingSchemaToModel(d)
+func (r *roleMappingResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	state := roleMappingResourceModel{}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	err := cli.GetRequestContext().UpsertRoleMapping(roleMapping)
-	if err != nil {
-		return errors.New(fmt.Sprintf("Error creating role mapping for role '%s': %s", roleMapping.Role, err.Error()))
+	timeout, diags := getFrameworkTimeout(ctx, state.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	d.SetId(roleMapping.Role)
-	return resourceOpensearchRoleMappingRead(d, meta)
-}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-func resourceOpensearchRoleMappingUpdate(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	roleMapping := roleMappingSchemaToModel(d)
+	role := state.Id.ValueString()
+	roleMapping, err := cli.GetRequestContext(ctx).GetRoleMapping(role)
+	if IsNotFound(err) {
+		resp.Diagnostics.AddWarning("Role mapping not found", fmt.Sprintf("Role mapping for role '%s' was not found, removing it from the state", role))
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
-	err := cli.GetRequestContext().UpsertRoleMapping(roleMapping)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error updating role mapping for role '%s': %s", roleMapping.Role, err.Error()))
+		resp.Diagnostics.AddError("Error retrieving role mapping", fmt.Sprintf("Error retrieving role mapping for role '%s': %s", role, err.Error()))
+		return
 	}
 
-	return resourceOpensearchRoleMappingRead(d, meta)
+	state.Role = types.StringValue(role)
+	state.BackendRoles, diags = stringsToFrameworkSet(ctx, roleMapping.BackendRoles, state.BackendRoles)
+	resp.Diagnostics.Append(diags...)
+	state.Hosts, diags = stringsToFrameworkSet(ctx, roleMapping.Hosts, state.Hosts)
+	resp.Diagnostics.Append(diags...)
+	state.Users, diags = stringsToFrameworkSet(ctx, roleMapping.Users, state.Users)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-func resourceOpensearchRoleMappingRead(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	role := d.Id()
+func (r *roleMappingResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	plan := roleMappingResourceModel{}
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	roleMapping, err := cli.GetRequestContext().GetRoleMapping(role)
-	if err != nil {
-		return errors.New(fmt.Sprintf("Error retrieving role mapping for role '%s': %s", role, err.Error()))
+	timeout, diags := getFrameworkTimeout(ctx, plan.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	roleMapping, diags := roleMappingResourceModelToModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	d.Set("role", role)
-	d.Set("backend_roles", roleMapping.BackendRoles)
-	d.Set("hosts", roleMapping.Hosts)
-	d.Set("users", roleMapping.Users)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := cli.GetRequestContext(ctx).UpsertRoleMapping(roleMapping)
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating role mapping", fmt.Sprintf("Error updating role mapping for role '%s': %s", roleMapping.Role, err.Error()))
+		return
+	}
 
-	return nil
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceOpensearchRoleMappingDelete(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	role := d.Id()
+func (r *roleMappingResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	state := roleMappingResourceModel{}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	err := cli.GetRequestContext().DeleteRoleMapping(role)
-	if err != nil {
-		return errors.New(fmt.Sprintf("Error deleting role mapping for role '%s': %s", role, err.Error()))
+	timeout, diags := getFrameworkTimeout(ctx, state.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	return nil
-}
\ No newline at end of file
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	role := state.Id.ValueString()
+	err := cli.GetRequestContext(ctx).DeleteRoleMapping(role)
+	if err != nil && !IsNotFound(err) {
+		resp.Diagnostics.AddError("Error deleting role mapping", fmt.Sprintf("Error deleting role mapping for role '%s': %s", role, err.Error()))
+	}
+}
@@ -1,137 +1,260 @@
 package provider
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
-func resourceOpensearchUser() *schema.Resource {
-	return &schema.Resource{
-		Description: "Opensearch user.",
-		Create: resourceOpensearchUserCreate,
-		Update: resourceOpensearchUserUpdate,
-		Read:   resourceOpensearchUserRead,
-		Delete: resourceOpensearchUserDelete,
-		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
-		},
-		Schema: map[string]*schema.Schema{
-			"username": {
-				Description: "Username of the user.",
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     true,
-				ValidateFunc: validation.StringIsNotEmpty,
-			},
-			"password": {
-				Description: "Password of the user.",
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     false,
-				ValidateFunc: validation.StringIsNotEmpty,
+type userResource struct {
+	data *frameworkProviderData
+}
+
+type userResourceModel struct {
+	Id            types.String `tfsdk:"id"`
+	Username      types.String `tfsdk:"username"`
+	Password      types.String `tfsdk:"password"`
+	SecurityRoles types.Set    `tfsdk:"opendistro_security_roles"`
+	BackendRoles  types.Set    `tfsdk:"backend_roles"`
+	Timeouts      types.Object `tfsdk:"timeouts"`
+}
+
+func NewUserResource() resource.Resource {
+	return &userResource{}
+}
+
+func (r *userResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+func (r *userResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Opensearch user.",
+		Version:             1,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The ID of this resource.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"opendistro_security_roles": {
-				Description: "Prebuilt security roles to assign to the user.",
-				Type:     schema.TypeSet,
-				Optional: true,
-				ForceNew: false,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+			"username": schema.StringAttribute{
+				MarkdownDescription: "Username of the user.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
 				},
 			},
-			"backend_roles": {
-				Description: "Custom roles to assign to the user.",
-				Type:     schema.TypeSet,
-				Optional: true,
-				ForceNew: false,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password of the user.",
+				Required:            true,
+				Validators: []validator.String{
+					stringNotEmptyValidator{},
 				},
 			},
+			"opendistro_security_roles": schema.SetAttribute{
+				MarkdownDescription: "Prebuilt security roles to assign to the user.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"backend_roles": schema.SetAttribute{
+				MarkdownDescription: "Custom roles to assign to the user.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": frameworkTimeoutsBlock(),
 		},
 	}
 }
 
-func userSchemaToModel(d *schema.ResourceData) UserModel {
-	model := UserModel{Username: "", Password: "", SecurityRoles: []string{}, BackendRoles: []string{}}
+func (r *userResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data, diags := getFrameworkProviderData(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.data = data
+}
 
-	username, _ := d.GetOk("username")
-	model.Username = username.(string)
+func (r *userResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				state := userResourceModel{}
+				resp.Diagnostics.Append(getSdkState(ctx, req, resp, &state)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
 
-	password, _ := d.GetOk("password")
-	model.Password = password.(string)
+				var diags diag.Diagnostics
+				state.SecurityRoles = sdkSetToFrameworkSet(ctx, state.SecurityRoles)
+				state.BackendRoles = sdkSetToFrameworkSet(ctx, state.BackendRoles)
+				state.Timeouts, diags = sdkTimeoutsToFrameworkTimeouts(ctx, state.Timeouts)
+				resp.Diagnostics.Append(diags...)
 
-	securityRoles, securityRolesExist := d.GetOk("opendistro_security_roles")
-	if securityRolesExist {
-		for _, val := range (securityRoles.(*schema.Set)).List() {
-			role := val.(string)
-			model.SecurityRoles = append(model.SecurityRoles, role)
-		}
+				resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			},
+		},
 	}
+}
+
+func (r *userResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func userResourceModelToModel(ctx context.Context, m userResourceModel) (UserModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var setDiags diag.Diagnostics
 
-	backendRoles, backendRolesExist := d.GetOk("backend_roles")
-	if backendRolesExist {
-		for _, val := range (backendRoles.(*schema.Set)).List() {
-			role := val.(string)
-			model.BackendRoles = append(model.BackendRoles, role)
-		}
+	model := UserModel{
+		Username: m.Username.ValueString(),
+		Password: m.Password.ValueString(),
 	}
 
-	return model
+	model.SecurityRoles, setDiags = frameworkSetToStrings(ctx, m.SecurityRoles)
+	diags.Append(setDiags...)
+	model.BackendRoles, setDiags = frameworkSetToStrings(ctx, m.BackendRoles)
+	diags.Append(setDiags...)
+
+	return model, diags
 }
 
-func resourceOpensearchUserCreate(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	user := userSchemaToModel(d)
+func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	plan := userResourceModel{}
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkTimeout(ctx, plan.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	user, diags := userResourceModelToModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	err := cli.GetRequestContext().UpsertUser(user)
+	err := cli.GetRequestContext(ctx).UpsertUser(user)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error creating user '%s': %s", user.Username, err.Error()))
+		resp.Diagnostics.AddError("Error creating user", fmt.Sprintf("Error creating user '%s': %s", user.Username, err.Error()))
+		return
 	}
 
-	d.SetId(user.Username)
-	return resourceOpensearchUserRead(d, meta)
+	plan.Id = types.StringValue(user.Username)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceOpensearchUserRead(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	username := d.Id()
+//The api does not return the password, so the one in the state is kept as is
+func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	state := userResourceModel{}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkTimeout(ctx, state.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	username := state.Id.ValueString()
+	user, err := cli.GetRequestContext(ctx).GetUser(username)
+	if IsNotFound(err) {
+		resp.Diagnostics.AddWarning("User not found", fmt.Sprintf("User '%s' was not found, removing it from the state", username))
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
-	user, err := cli.GetRequestContext().GetUser(username)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error retrieving existing user '%s': %s", username, err.Error()))
+		resp.Diagnostics.AddError("Error retrieving user", fmt.Sprintf("Error retrieving existing user '%s': %s", username, err.Error()))
+		return
 	}
 
-	d.Set("username", username)
-	d.Set("opendistro_security_roles", user.SecurityRoles)
-	d.Set("backend_roles", user.BackendRoles)
+	state.Username = types.StringValue(username)
+	state.SecurityRoles, diags = stringsToFrameworkSet(ctx, user.SecurityRoles, state.SecurityRoles)
+	resp.Diagnostics.Append(diags...)
+	state.BackendRoles, diags = stringsToFrameworkSet(ctx, user.BackendRoles, state.BackendRoles)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	return nil
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-func resourceOpensearchUserUpdate(d *schema.ResourceData, meta interface{}) error {
-	cli := meta.(OpensearchClient)
-	user := userSchemaToModel(d)
+func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	plan := userResourceModel{}
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := getFrameworkTimeout(ctx, plan.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	user, diags := userResourceModelToModel(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	err := cli.GetRequestContext().UpsertUser(user)
+	err := cli.GetRequestContext(ctx).UpsertUser(user)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error updating existing user '%s': %s", user.Username, err.Error()))
+		resp.Diagnostics.AddError("Error updating user", fmt.Sprintf("Error updating existing user '%s': %s", user.Username, err.Error()))
+		return
 	}
 
-	return resourceOpensearchUserRead(d, meta)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
-func resourceOpensearchUserDelete(d *schema.ResourceData, meta interface{}) error {
-	username := d.Id()
-	cli := meta.(OpensearchClient)
+func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	state := userResourceModel{}
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	err := cli.GetRequestContext().DeleteUser(username)
-	if err != nil {
-		return errors.New(fmt.Sprintf("Error deleting existing user '%s': %s", username, err.Error()))
+	timeout, diags := getFrameworkTimeout(ctx, state.Timeouts, 5*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	cli, diags := r.data.GetClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	return nil
-}
\ No newline at end of file
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	username := state.Id.ValueString()
+	err := cli.GetRequestContext(ctx).DeleteUser(username)
+	if err != nil && !IsNotFound(err) {
+		resp.Diagnostics.AddError("Error deleting user", fmt.Sprintf("Error deleting existing user '%s': %s", username, err.Error()))
+	}
+}